@@ -0,0 +1,31 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// expandBundles returns the packages of the named bundles, in order.
+func expandBundles(names []string, bundles map[string][]string) ([]string, error) {
+	var pkgs []string
+	for _, name := range names {
+		bundle, ok := bundles[name]
+		if !ok {
+			available := make([]string, 0, len(bundles))
+			for n := range bundles {
+				available = append(available, n)
+			}
+			sort.Strings(available)
+			if len(available) == 0 {
+				return nil, fmt.Errorf("unknown bundle %s, add bundles to gopack.json", name)
+			}
+			return nil, fmt.Errorf("unknown bundle %s (available: %s)", name, strings.Join(available, ", "))
+		}
+		if len(bundle) == 0 {
+			return nil, fmt.Errorf("bundle %s is empty", name)
+		}
+		pkgs = append(pkgs, bundle...)
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandBundles(t *testing.T) {
+	bundles := map[string][]string{
+		"web": {"github.com/go-chi/chi/v5@latest", "github.com/go-chi/cors@v1.2.1"},
+		"log": {"go.uber.org/zap@v1.26.0"},
+		"new": {},
+	}
+
+	pkgs, err := expandBundles([]string{"web", "log"}, bundles)
+	if err != nil {
+		t.Fatalf("expandBundles failed: %v", err)
+	}
+	expected := []string{"github.com/go-chi/chi/v5@latest", "github.com/go-chi/cors@v1.2.1", "go.uber.org/zap@v1.26.0"}
+	if !reflect.DeepEqual(pkgs, expected) {
+		t.Errorf("Expected %v, got %v", expected, pkgs)
+	}
+
+	for _, name := range []string{"db", "new"} {
+		if _, err := expandBundles([]string{name}, bundles); err == nil {
+			t.Errorf("Expected an error for bundle %s", name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+)
+
+func cache() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cached search results",
+		Long: "Search results are cached in the gopack directory of the user cache directory and reused until they expire.\n" +
+			"Set how long they are kept with cacheTTL in the search section of gopack.json, e.g. \"12h\", or \"0\" to disable the cache.",
+	}
+
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:     "clear",
+		Short:   "Remove every cached search result",
+		Example: "gopack cache clear",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := config.CacheDir()
+			if err != nil {
+				return err
+			}
+			if err := util.ClearSearchCache(dir); err != nil {
+				return err
+			}
+			fmt.Println("Cleared the search cache")
+			return nil
+		},
+	})
+
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:     "info",
+		Short:   "Show where the cache is and how much it holds",
+		Example: "gopack cache info\ngopack cache info -o json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			dir, err := config.CacheDir()
+			if err != nil {
+				return err
+			}
+			info, err := util.SearchCacheInfo(dir)
+			if err != nil {
+				return err
+			}
+
+			if format == outputJSON {
+				return printJSON(info)
+			}
+
+			fmt.Printf("Directory: %s\n", info.Dir)
+			fmt.Printf("Searches:  %d\n", info.Entries)
+			fmt.Printf("Size:      %s\n", formatSize(info.Size))
+			if info.Entries > 0 {
+				fmt.Printf("Oldest:    %s\n", info.Oldest.Format(time.DateTime))
+				fmt.Printf("Newest:    %s\n", info.Newest.Format(time.DateTime))
+			}
+			return nil
+		},
+	})
+
+	return cacheCmd
+}
+
+// formatSize formats a number of bytes with a binary unit.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
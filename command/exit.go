@@ -0,0 +1,72 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/juancwu/gopack/util"
+)
+
+// Exit codes of commands that install packages
+const (
+	// ExitOK means every query was installed
+	ExitOK = 0
+	// ExitPartialFailure means at least one query could not be installed
+	ExitPartialFailure = 1
+	// ExitSearchFailure means at least one search failed, e.g. the network is down
+	ExitSearchFailure = 2
+	// ExitNothingMatched means none of the queries matched a package
+	ExitNothingMatched = 3
+	// ExitInterrupted means the command was stopped with ctrl+c or a kill signal
+	ExitInterrupted = 130
+)
+
+// ExitError asks main to exit with Code, the details were already printed by the command
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode maps the install results to one of the exit codes.
+func exitCode(results []util.InstallResult) int {
+	failed, noMatch := 0, 0
+	for _, r := range results {
+		switch r.Status {
+		case util.StatusOK:
+			continue
+		case util.StatusSearchFailed:
+			return ExitSearchFailure
+		case util.StatusNoMatch:
+			noMatch++
+		}
+		failed++
+	}
+
+	switch {
+	case failed == 0:
+		return ExitOK
+	case noMatch == len(results):
+		return ExitNothingMatched
+	default:
+		return ExitPartialFailure
+	}
+}
+
+// resultsError returns an ExitError when any of the results failed, nil otherwise.
+func resultsError(results []util.InstallResult) error {
+	code := exitCode(results)
+	if code == ExitOK {
+		return nil
+	}
+	return ExitError{
+		Code: code,
+		Err:  fmt.Errorf("failed to install %d of %d packages", countFailed(results), len(results)),
+	}
+}
@@ -0,0 +1,134 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/module"
+)
+
+func fav() *cobra.Command {
+	favCmd := &cobra.Command{
+		Use:     "fav",
+		Aliases: []string{"favorites"},
+		Short:   "Manage the starred packages",
+		Long: "Starred packages are kept in the user wide gopack.json, inside the gopack directory of the user config directory.\n" +
+			"Install them all with 'gop get @fav', they are not searched. Star search results with 's' in the TUI.",
+	}
+
+	favCmd.AddCommand(&cobra.Command{
+		Use:     "add IMPORT_PATH[@VERSION]...",
+		Short:   "Star packages, optionally pinning the version installed with @fav",
+		Example: "gopack fav add github.com/go-chi/chi/v5\ngopack fav add go.uber.org/zap@v1.26.0",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				path, version := util.SplitQuery(arg)
+				if err := module.CheckImportPath(path); err != nil {
+					return fmt.Errorf("not an import path: %v", err)
+				}
+				if err := config.AddFavorite(config.Favorite{Path: path, Version: version}); err != nil {
+					return err
+				}
+				fmt.Println("Starred", arg)
+			}
+			return nil
+		},
+	})
+
+	favCmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the starred packages",
+		Example: "gopack fav list\ngopack fav list -o json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			favorites, err := config.LoadFavorites()
+			if err != nil {
+				return err
+			}
+			return printFavorites(format, favorites)
+		},
+	})
+
+	favCmd.AddCommand(&cobra.Command{
+		Use:     "rm IMPORT_PATH...",
+		Aliases: []string{"remove"},
+		Short:   "Unstar packages",
+		Example: "gopack fav rm github.com/go-chi/chi/v5",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				path, _ := util.SplitQuery(arg)
+				found, err := config.RemoveFavorite(path)
+				if err != nil {
+					return err
+				}
+				if !found {
+					return fmt.Errorf("%s is not starred", path)
+				}
+				fmt.Println("Unstarred", path)
+			}
+			return nil
+		},
+	})
+
+	return favCmd
+}
+
+func printFavorites(format string, favorites []config.Favorite) error {
+	switch format {
+	case outputJSON:
+		return printJSON(favorites)
+	case outputTable:
+		w := newTableWriter()
+		fmt.Fprintln(w, "PATH\tVERSION\tSYNOPSIS")
+		for _, f := range favorites {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", f.Path, f.Version, f.Synopsis)
+		}
+		return w.Flush()
+	default:
+		if len(favorites) == 0 {
+			fmt.Println("No starred packages")
+			return nil
+		}
+		for _, f := range favorites {
+			fmt.Println(util.JoinVersion(f.Path, f.Version))
+		}
+		return nil
+	}
+}
+
+// expandFavorites replaces the @fav query with the starred packages, at their pinned version.
+func expandFavorites(queries []string, favorites []config.Favorite) ([]string, error) {
+	var expanded []string
+	for _, query := range queries {
+		if query != util.FavoritesQuery {
+			expanded = append(expanded, query)
+			continue
+		}
+		if len(favorites) == 0 {
+			return nil, fmt.Errorf("no starred packages for %s, star some with 'gop fav add'", util.FavoritesQuery)
+		}
+		for _, f := range favorites {
+			expanded = append(expanded, util.JoinVersion(f.Path, f.Version))
+		}
+	}
+	return expanded, nil
+}
+
+// favoriteQueries returns the starred packages as queries, none when they can't be read.
+func favoriteQueries() []string {
+	favorites, err := config.LoadFavorites()
+	if err != nil {
+		return nil
+	}
+	queries, _ := expandFavorites([]string{util.FavoritesQuery}, favorites)
+	return queries
+}
@@ -0,0 +1,28 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/juancwu/gopack/config"
+)
+
+func TestExpandFavorites(t *testing.T) {
+	favorites := []config.Favorite{
+		{Path: "github.com/go-chi/chi/v5"},
+		{Path: "go.uber.org/zap", Version: "v1.26.0"},
+	}
+
+	expanded, err := expandFavorites([]string{"cors", "@fav"}, favorites)
+	if err != nil {
+		t.Fatalf("expandFavorites failed: %v", err)
+	}
+	expected := []string{"cors", "github.com/go-chi/chi/v5", "go.uber.org/zap@v1.26.0"}
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("Expected %v, got %v", expected, expanded)
+	}
+
+	if _, err := expandFavorites([]string{"@fav"}, nil); err == nil {
+		t.Errorf("Expected an error without starred packages")
+	}
+}
@@ -1,28 +1,145 @@
 package command
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/juancwu/gopack/config"
 	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
 	"github.com/spf13/cobra"
 )
 
 func get() *cobra.Command {
 	var selectResult bool
+	var noTUI bool
+	var strict bool
+	var bundleNames []string
 	getCmd := &cobra.Command{
-		Use:     "get",
-		Short:   "Search and install first in result",
-		Long:    "Search and install first in query result with a confirmation. There is a chance to look all results.",
-		Example: "gopack install PKG_NAME",
-		Args:    cobra.MinimumNArgs(1),
+		Use:   "get",
+		Short: "Search and install first in result",
+		Long: "Search and install first in query result with a confirmation. There is a chance to look all results.\n" +
+			"Pin a version with QUERY@VERSION, any version query accepted by 'go get' works (v1.2.3, latest, upgrade, patch, a branch or commit).\n" +
+			"The query @fav installs every starred package, see 'gop fav'. --bundle installs the packages of a bundle from gopack.json.\n\n" +
+			"Exit codes: 0 all packages installed, 1 some packages failed, 2 a search failed, 3 nothing matched.",
+		Example: "gopack get PKG_NAME\ngopack get chi@v5.0.12 cors@latest\ngopack get -s PKG_NAME\ngopack get @fav\ngopack get --bundle web",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			m := tui.NewInstallModel(args, selectResult)
-			p := tea.NewProgram(m)
-			_, err := p.Run()
-			return err
+			if len(bundleNames) > 0 {
+				bundles, err := config.LoadBundles()
+				if err != nil {
+					return err
+				}
+				pkgs, err := expandBundles(bundleNames, bundles)
+				if err != nil {
+					return err
+				}
+				args = append(args, pkgs...)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("no package specified")
+			}
+
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			searcher, err := newSearcher(cmd)
+			if err != nil {
+				return err
+			}
+			// a broken favorites file only gets in the way of the queries that use it
+			if slices.Contains(args, util.FavoritesQuery) {
+				favorites, err := config.LoadFavorites()
+				if err != nil {
+					return err
+				}
+				if args, err = expandFavorites(args, favorites); err != nil {
+					return err
+				}
+			}
+
+			if noTUI || !isInteractive() {
+				if selectResult {
+					return fmt.Errorf("--select needs an interactive terminal")
+				}
+				// progress goes to stderr when stdout is reserved for the results
+				var progress io.Writer = os.Stdout
+				if format != "" {
+					progress = os.Stderr
+				}
+				results := runHeadlessGet(cmd.Context(), progress, searcher, args, strict, isOffline(cmd))
+				recordHistory(historyGet, results)
+				if format != "" {
+					if err := printInstallResults(format, results); err != nil {
+						return err
+					}
+				} else {
+					printSummary(progress, results)
+				}
+				return resultsError(results)
+			}
+
+			m := tui.NewInstallModel(cmd.Context(), args, !selectResult, searcher)
+			m.SetOffline(isOffline(cmd))
+
+			// keep stdout clean for the results when machine readable output is requested
+			opts := []tea.ProgramOption{tea.WithContext(cmd.Context())}
+			if format != "" {
+				opts = append(opts, tea.WithOutput(os.Stderr))
+			}
+			p := tea.NewProgram(m, opts...)
+			final, err := p.Run()
+			if err != nil {
+				return err
+			}
+
+			results := tui.Results(final)
+			recordHistory(historyGet, results)
+			if format != "" {
+				if err := printInstallResults(format, results); err != nil {
+					return err
+				}
+			} else if countFailed(results) > 0 {
+				printSummary(os.Stderr, results)
+			}
+			return resultsError(results)
 		},
 	}
 
-	getCmd.Flags().BoolVarP(&selectResult, "select", "s", false, "Show list of results and allow manual selection")
+	getCmd.Flags().BoolVarP(&selectResult, "select", "s", false, "Show list of results and versions and allow manual selection")
+	getCmd.Flags().BoolVarP(&noTUI, "yes", "y", false, "Install the first match of every query without the TUI, same as --no-tui")
+	getCmd.Flags().BoolVar(&noTUI, "no-tui", false, "Install the first match of every query without the TUI")
+	getCmd.Flags().StringSliceVarP(&bundleNames, "bundle", "b", nil, "Install the packages of the bundle from gopack.json, can be repeated")
+	getCmd.Flags().BoolVar(&strict, "strict", false, "Without the TUI, fail queries that match several packages instead of installing the first")
 
 	return getCmd
 }
+
+func printInstallResults(format string, results []util.InstallResult) error {
+	switch format {
+	case outputJSON:
+		if results == nil {
+			results = []util.InstallResult{}
+		}
+		return printJSON(results)
+	case outputTable:
+		w := newTableWriter()
+		fmt.Fprintln(w, "QUERY\tPATH\tVERSION\tERROR")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Query, r.Path, r.Version, r.Error)
+		}
+		return w.Flush()
+	default:
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Printf("%s: error: %s\n", r.Query, r.Error)
+				continue
+			}
+			fmt.Printf("%s: %s\n", r.Query, util.JoinVersion(r.Path, r.Version))
+		}
+		return nil
+	}
+}
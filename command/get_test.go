@@ -0,0 +1,102 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/juancwu/gopack/util"
+)
+
+// fakeSearcher answers searches from a map, unknown terms fail with a network error
+type fakeSearcher map[string][]util.SearchResult
+
+func (s fakeSearcher) Search(ctx context.Context, term string) ([]util.SearchResult, error) {
+	results, ok := s[term]
+	if !ok {
+		return nil, util.NetworkError{URL: "fake://search", Err: errors.New("offline")}
+	}
+	return results, nil
+}
+
+func TestRunHeadlessGet(t *testing.T) {
+	searcher := fakeSearcher{
+		"nothing": {},
+		"chi": {
+			{ImportPath: "github.com/go-chi/chi"},
+			{ImportPath: "github.com/go-chi/chi/v5"},
+		},
+	}
+
+	var buf bytes.Buffer
+	results := runHeadlessGet(context.Background(), &buf, searcher, []string{"offline", "nothing", "chi"}, true, false)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	// Every query fails before reaching go get
+	for _, r := range results {
+		if r.Error == "" {
+			t.Errorf("Expected query '%s' to fail, got %+v", r.Query, r)
+		}
+	}
+	expectedStatus := []string{util.StatusSearchFailed, util.StatusNoMatch, util.StatusAmbiguous}
+	for i, status := range expectedStatus {
+		if results[i].Status != status {
+			t.Errorf("Expected query '%s' to have status '%s', got '%s'", results[i].Query, status, results[i].Status)
+		}
+	}
+
+	if countFailed(results) != 3 {
+		t.Errorf("Expected 3 failed queries, got %d", countFailed(results))
+	}
+
+	printSummary(&buf, results)
+	if !strings.Contains(buf.String(), "Installed 0 of 3 packages") {
+		t.Errorf("Expected summary in output, got: %s", buf.String())
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	ok := util.InstallResult{Query: "chi", Status: util.StatusOK}
+	searchFailed := util.InstallResult{Query: "cors", Status: util.StatusSearchFailed, Error: "offline"}
+	noMatch := util.InstallResult{Query: "nothing", Status: util.StatusNoMatch, Error: "no packages found"}
+	installFailed := util.InstallResult{Query: "chi", Status: util.StatusInstallFailed, Error: "exit status 1"}
+	cancelled := util.InstallResult{Query: "cors", Status: util.StatusCancelled, Error: "quit before picking a package"}
+
+	tests := []struct {
+		name    string
+		results []util.InstallResult
+		code    int
+	}{
+		{"All installed", []util.InstallResult{ok, ok}, ExitOK},
+		{"Partial failure", []util.InstallResult{ok, installFailed}, ExitPartialFailure},
+		{"Some queries without match", []util.InstallResult{ok, noMatch}, ExitPartialFailure},
+		{"Search failure", []util.InstallResult{ok, noMatch, searchFailed}, ExitSearchFailure},
+		{"Nothing matched", []util.InstallResult{noMatch, noMatch}, ExitNothingMatched},
+		{"Quit before installing", []util.InstallResult{cancelled, cancelled}, ExitPartialFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCode(tt.results); code != tt.code {
+				t.Errorf("Expected exit code %d, got %d", tt.code, code)
+			}
+
+			err := resultsError(tt.results)
+			if tt.code == ExitOK {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			var exitErr ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != tt.code {
+				t.Errorf("Expected ExitError with code %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
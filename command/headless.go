@@ -0,0 +1,126 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/juancwu/gopack/util"
+	"github.com/mattn/go-isatty"
+)
+
+// isInteractive reports whether stdout is a terminal that can show the TUI.
+func isInteractive() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// runHeadlessGet searches and installs every query without a TUI, writing progress lines to w.
+// The searches run concurrently and the picked packages are added with a single 'go get'.
+// The first match is installed, in strict mode a query with several matches fails instead.
+// Offline, the packages come from the module cache at the version found by the search.
+func runHeadlessGet(ctx context.Context, w io.Writer, searcher util.Searcher, queries []string, strict bool, offline bool) []util.InstallResult {
+	results := make([]util.InstallResult, len(queries))
+
+	terms := make([]string, len(queries))
+	versions := make([]string, len(queries))
+	for i, query := range queries {
+		results[i] = util.InstallResult{Query: query, Status: util.StatusOK}
+		terms[i], versions[i] = util.SplitQuery(query)
+	}
+
+	fmt.Fprintf(w, "Searching %s\n", quoteAll(terms))
+	outcomes := util.SearchAll(ctx, searcher, terms)
+
+	// indexes of the results that have a package to install
+	var picked []int
+	var modules, pkgs []string
+	for i, outcome := range outcomes {
+		term := terms[i]
+		var stale util.StaleResultsError
+		if errors.As(outcome.Err, &stale) {
+			fmt.Fprintf(w, "Warning: search for '%s' failed, %s\n", term, stale.Error())
+			outcome.Err = nil
+		}
+		if outcome.Err != nil {
+			results[i].Status = util.StatusSearchFailed
+			results[i].Error = outcome.Err.Error()
+			fmt.Fprintf(w, "Error searching '%s': %s\n", term, results[i].Error)
+			continue
+		}
+
+		match, err := util.PickResult(term, outcome.Results, strict)
+		if err != nil {
+			results[i].Status = util.StatusAmbiguous
+			if len(outcome.Results) == 0 {
+				results[i].Status = util.StatusNoMatch
+			}
+			results[i].Error = err.Error()
+			fmt.Fprintf(w, "Error picking a package for '%s': %s\n", term, results[i].Error)
+			continue
+		}
+
+		if offline && versions[i] == "" {
+			versions[i] = match.Version
+		}
+		results[i].Path, results[i].Version = match.ImportPath, versions[i]
+		picked = append(picked, i)
+		modules = append(modules, match.Module())
+		pkgs = append(pkgs, util.JoinVersion(match.ImportPath, versions[i]))
+	}
+
+	if len(pkgs) == 0 {
+		return results
+	}
+
+	fmt.Fprintf(w, "Installing %s\n", quoteAll(pkgs))
+	errs := util.NewGoRunner(offline).GetAll(ctx, pkgs)
+	for j, i := range picked {
+		if errs[j] != nil {
+			results[i].Status = util.StatusInstallFailed
+			results[i].Error = errs[j].Error()
+			fmt.Fprintf(w, "Error installing '%s': %s\n", pkgs[j], results[i].Error)
+			continue
+		}
+		if v, err := util.ModuleVersion(ctx, modules[j]); err == nil {
+			results[i].Version = v
+		}
+		fmt.Fprintf(w, "Successfully installed '%s'\n", util.JoinVersion(results[i].Path, results[i].Version))
+	}
+
+	return results
+}
+
+// quoteAll quotes every string and joins them with commas.
+func quoteAll(strs []string) string {
+	quoted := make([]string, len(strs))
+	for i, s := range strs {
+		quoted[i] = "'" + s + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// printSummary writes how many queries were installed followed by every failure.
+func printSummary(w io.Writer, results []util.InstallResult) {
+	failed := countFailed(results)
+	fmt.Fprintf(w, "\nInstalled %d of %d packages\n", len(results)-failed, len(results))
+	for _, r := range results {
+		if r.Error != "" {
+			// indent the captured go output under the query
+			fmt.Fprintf(w, "  %s: %s\n", r.Query, strings.ReplaceAll(r.Error, "\n", "\n    "))
+		}
+	}
+}
+
+func countFailed(results []util.InstallResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
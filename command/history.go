@@ -0,0 +1,243 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	historyGet     = "get"
+	historyInstall = "install"
+)
+
+func history() *cobra.Command {
+	var listEntries bool
+	var clearHistory bool
+	var here bool
+	var limit int
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse past searches and install past picks again",
+		Long: "Every query searched with get, install or the interactive mode is recorded with the package picked, its version,\n" +
+			"the project directory and the outcome. Without flags, pick past packages from a list to install them again at the same version.",
+		Example: "gopack history\ngopack history --list -n 20\ngopack history -o json\ngopack history --clear",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearHistory {
+				if err := config.ClearHistory(); err != nil {
+					return err
+				}
+				fmt.Println("Cleared the history")
+				return nil
+			}
+
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			entries, err := config.LoadHistory()
+			if err != nil {
+				return err
+			}
+			if here {
+				entries = entriesInDir(entries)
+			}
+
+			if listEntries || format != "" || !isInteractive() {
+				// newest first, like the list to pick from
+				reversed := make([]config.HistoryEntry, 0, len(entries))
+				for i := len(entries) - 1; i >= 0 && (limit <= 0 || len(reversed) < limit); i-- {
+					reversed = append(reversed, entries[i])
+				}
+				return printHistory(format, reversed)
+			}
+
+			picks := pastPicks(entries)
+			if len(picks) == 0 {
+				fmt.Println("Nothing installed yet")
+				return nil
+			}
+
+			m := tui.NewHistoryModel(picks)
+			p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(cmd.Context()))
+			final, err := p.Run()
+			if err != nil {
+				return err
+			}
+
+			selected := tui.SelectedHistory(final)
+			if len(selected) == 0 {
+				fmt.Println("Nothing to install")
+				return nil
+			}
+			results := installAgain(cmd.Context(), util.NewGoRunner(isOffline(cmd)), selected)
+			printSummary(os.Stdout, results)
+			return resultsError(results)
+		},
+	}
+
+	historyCmd.Flags().BoolVarP(&listEntries, "list", "l", false, "Print the history instead of picking packages to install again")
+	historyCmd.Flags().BoolVar(&clearHistory, "clear", false, "Remove the whole history")
+	historyCmd.Flags().BoolVar(&here, "here", false, "Only show the history of the current directory")
+	historyCmd.Flags().IntVarP(&limit, "limit", "n", 0, "Print at most this many entries, newest first")
+
+	return historyCmd
+}
+
+// recordHistory appends the results of the queries to the history. A history that can't be
+// written is not worth failing the command for.
+func recordHistory(command string, results []util.InstallResult) {
+	var dir string
+	if command == historyGet {
+		// binaries don't belong to a project
+		dir, _ = os.Getwd()
+	}
+
+	now := time.Now()
+	entries := make([]config.HistoryEntry, len(results))
+	for i, r := range results {
+		entries[i] = config.HistoryEntry{
+			Time:    now,
+			Command: command,
+			Query:   r.Query,
+			Path:    r.Path,
+			Version: r.Version,
+			Dir:     dir,
+			Status:  r.Status,
+			Error:   r.Error,
+		}
+	}
+	if err := config.AppendHistory(entries...); err != nil {
+		log.Warn("could not record the history", "err", err)
+	}
+}
+
+// recentQueries returns the past queries for the interactive mode, none when the history can't be read.
+func recentQueries() []string {
+	entries, err := config.LoadHistory()
+	if err != nil {
+		return nil
+	}
+	return config.RecentQueries(entries)
+}
+
+// entriesInDir keeps the entries recorded in the current directory.
+func entriesInDir(entries []config.HistoryEntry) []config.HistoryEntry {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	var kept []config.HistoryEntry
+	for _, entry := range entries {
+		if entry.Dir == wd {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// pastPicks returns the packages installed successfully, newest first, each package and
+// version only once.
+func pastPicks(entries []config.HistoryEntry) []config.HistoryEntry {
+	seen := map[string]bool{}
+	var picks []config.HistoryEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		key := entry.Command + " " + util.JoinVersion(entry.Path, entry.Version)
+		if entry.Status != util.StatusOK || entry.Path == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		picks = append(picks, entry)
+	}
+	return picks
+}
+
+// installAgain installs the past picks at their recorded version, dependencies with a single
+// 'go get' in the current module and binaries with 'go install'. The results are recorded again.
+func installAgain(ctx context.Context, runner util.GoRunner, entries []config.HistoryEntry) []util.InstallResult {
+	var deps, binaries []util.InstallResult
+	for _, entry := range entries {
+		result := util.InstallResult{Query: entry.Query, Path: entry.Path, Version: entry.Version, Status: util.StatusOK}
+		if entry.Command == historyInstall {
+			binaries = append(binaries, result)
+		} else {
+			deps = append(deps, result)
+		}
+	}
+
+	pkgs := make([]string, len(deps))
+	for i, r := range deps {
+		pkgs[i] = util.JoinVersion(r.Path, r.Version)
+	}
+	for i, err := range runner.GetAll(ctx, pkgs) {
+		if err != nil {
+			deps[i].Status = util.StatusInstallFailed
+			deps[i].Error = err.Error()
+		}
+	}
+	if len(deps) > 0 {
+		recordHistory(historyGet, deps)
+	}
+
+	for i, r := range binaries {
+		version := r.Version
+		if version == "" {
+			version = "latest"
+		}
+		if err := runner.Install(ctx, util.JoinVersion(r.Path, version)); err != nil {
+			binaries[i].Status = util.StatusInstallFailed
+			binaries[i].Error = err.Error()
+			continue
+		}
+		if err := recordTool(ctx, r.Path, version); err != nil {
+			log.Warn("could not record installed tool", "pkg", r.Path, "err", err)
+		}
+	}
+	if len(binaries) > 0 {
+		recordHistory(historyInstall, binaries)
+	}
+
+	return append(deps, binaries...)
+}
+
+func printHistory(format string, entries []config.HistoryEntry) error {
+	switch format {
+	case outputJSON:
+		return printJSON(entries)
+	case outputTable:
+		w := newTableWriter()
+		fmt.Fprintln(w, "TIME\tCOMMAND\tQUERY\tPACKAGE\tDIR\tSTATUS")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.DateTime), e.Command, e.Query, util.JoinVersion(e.Path, e.Version), e.Dir, e.Status)
+		}
+		return w.Flush()
+	default:
+		if len(entries) == 0 {
+			fmt.Println("No history yet")
+			return nil
+		}
+		for _, e := range entries {
+			line := fmt.Sprintf("%s  gop %s %s", e.Time.Local().Format(time.DateTime), e.Command, e.Query)
+			if e.Path != "" {
+				line += " -> " + util.JoinVersion(e.Path, e.Version)
+			}
+			if e.Status != util.StatusOK {
+				line += " (" + e.Status + ")"
+			}
+			fmt.Println(line)
+		}
+		return nil
+	}
+}
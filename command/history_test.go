@@ -0,0 +1,29 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/util"
+)
+
+func TestPastPicks(t *testing.T) {
+	entries := []config.HistoryEntry{
+		{Command: historyGet, Query: "chi", Path: "github.com/go-chi/chi/v5", Version: "v5.0.11", Status: util.StatusOK},
+		{Command: historyGet, Query: "nothing", Status: util.StatusNoMatch},
+		{Command: historyGet, Query: "cors", Path: "github.com/go-chi/cors", Version: "v1.2.1", Status: util.StatusInstallFailed},
+		{Command: historyInstall, Query: "stringer", Path: "golang.org/x/tools/cmd/stringer", Version: "v0.20.0", Status: util.StatusOK},
+		{Command: historyGet, Query: "chi@v5.0.11", Path: "github.com/go-chi/chi/v5", Version: "v5.0.11", Status: util.StatusOK},
+	}
+
+	var got []string
+	for _, pick := range pastPicks(entries) {
+		got = append(got, pick.Query)
+	}
+	// newest first, the same package and version once
+	expected := []string{"chi@v5.0.11", "stringer"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+)
+
+func install() *cobra.Command {
+	var selectResult bool
+	var syncTools bool
+	var listTools bool
+
+	installCmd := &cobra.Command{
+		Use:     "install",
+		Short:   "Search and install binaries with go install",
+		Long:    "Search and install binaries with 'go install', same as get but for tools like linters and generators.\nInstalled tools are recorded so they can be installed again with --sync.",
+		Example: "gopack install golangci-lint\ngopack install stringer@v0.20.0\ngopack install --sync",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if listTools {
+				return handleListTools()
+			}
+			if syncTools {
+				return handleSyncTools(cmd.Context(), util.NewGoRunner(isOffline(cmd)))
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("no package specified")
+			}
+
+			searcher, err := newSearcher(cmd)
+			if err != nil {
+				return err
+			}
+			m := tui.NewInstallModel(cmd.Context(), args, !selectResult, searcher)
+			m.SetGoInstall(true)
+			m.SetOffline(isOffline(cmd))
+			p := tea.NewProgram(m, tea.WithContext(cmd.Context()))
+			final, err := p.Run()
+			if err != nil {
+				return err
+			}
+
+			results := tui.Results(final)
+			recordHistory(historyInstall, results)
+			for _, result := range results {
+				if result.Status != util.StatusOK {
+					continue
+				}
+				if err := recordTool(cmd.Context(), result.Path, result.Version); err != nil {
+					log.Warn("could not record installed tool", "pkg", result.Path, "err", err)
+				}
+			}
+			if countFailed(results) > 0 {
+				printSummary(os.Stderr, results)
+			}
+			return resultsError(results)
+		},
+	}
+
+	installCmd.Flags().BoolVarP(&selectResult, "select", "s", false, "Show list of results and versions and allow manual selection")
+	installCmd.Flags().BoolVar(&syncTools, "sync", false, "Install all the recorded tools again")
+	installCmd.Flags().BoolVarP(&listTools, "list", "l", false, "List the recorded tools")
+
+	return installCmd
+}
+
+// recordTool records the installed tool with the exact version found in the binary.
+func recordTool(ctx context.Context, path string, version string) error {
+	if dir, err := util.BinaryDir(ctx); err == nil {
+		// binaries built from a local checkout report (devel), keep the requested version then
+		if v, err := util.BinaryVersion(ctx, filepath.Join(dir, util.BinaryName(path))); err == nil && strings.HasPrefix(v, "v") {
+			version = v
+		}
+	}
+
+	return config.RecordTool(config.Tool{Path: path, Version: version})
+}
+
+func handleListTools() error {
+	tools, err := config.LoadTools()
+	if err != nil {
+		return err
+	}
+
+	if len(tools) == 0 {
+		fmt.Println("No tools installed with gop install")
+		return nil
+	}
+
+	fmt.Println("Installed tools:")
+	for _, tool := range tools {
+		fmt.Printf("  %s@%s\n", tool.Path, tool.Version)
+	}
+	return nil
+}
+
+func handleSyncTools(ctx context.Context, runner util.GoRunner) error {
+	tools, err := config.LoadTools()
+	if err != nil {
+		return err
+	}
+
+	if len(tools) == 0 {
+		fmt.Println("No tools installed with gop install")
+		return nil
+	}
+
+	dir, err := util.BinaryDir(ctx)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, tool := range tools {
+		pkg := util.JoinVersion(tool.Path, tool.Version)
+		fmt.Printf("Installing %s...\n", pkg)
+		if err := runner.Install(ctx, pkg); err != nil {
+			log.Error("failed to install tool", "pkg", pkg, "err", err)
+			failed++
+			continue
+		}
+		fmt.Printf("Installed %s to %s\n", pkg, filepath.Join(dir, util.BinaryName(tool.Path)))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to install %d of %d tools", failed, len(tools))
+	}
+	return nil
+}
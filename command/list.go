@@ -11,20 +11,33 @@ import (
 
 func list() *cobra.Command {
 	listCmd := &cobra.Command{
-		Use:     "list",
-		Short:   "List all the packages that was installed and used",
-		Long:    "List all the packages that was installed and used. And also going to show the path they they are installed and the version.",
-		Example: "gopack list",
+		Use:   "list",
+		Short: "List all the packages that was installed and used",
+		Long: "List all the packages that was installed and used. And also going to show the path they they are installed and the version.\n" +
+			"In the TUI, upgrade, pick the version of, remove, open the docs of, copy or see why the highlighted package is needed.",
+		Example: "gopack list\ngopack list -o json",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			packages, err := util.GetDependencyList()
+			format, err := outputFormat(cmd)
 			if err != nil {
+				return err
+			}
+
+			packages, err := util.GetDependencyList(cmd.Context())
+			if err != nil {
+				if format != "" {
+					return fmt.Errorf("error getting dependency list: %v", err)
+				}
 				fmt.Println("Error getting dependency list: ", err)
 			}
 
-			m := tui.NewListModel(packages)
-			m.List.Title = "Installed Packages"
+			if format != "" {
+				return printPackages(format, packages)
+			}
+
+			m := tui.NewListModel(cmd.Context(), packages)
+			m.SetOffline(isOffline(cmd))
 
-			p := tea.NewProgram(m, tea.WithAltScreen())
+			p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(cmd.Context()))
 			if _, err := p.Run(); err != nil {
 				fmt.Println("Error running program:", err)
 			}
@@ -33,3 +46,25 @@ func list() *cobra.Command {
 	}
 	return listCmd
 }
+
+func printPackages(format string, packages []util.Package) error {
+	switch format {
+	case outputJSON:
+		if packages == nil {
+			packages = []util.Package{}
+		}
+		return printJSON(packages)
+	case outputTable:
+		w := newTableWriter()
+		fmt.Fprintln(w, "PATH\tVERSION\tTYPE\tDIR")
+		for _, pkg := range packages {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pkg.Path, pkg.Version, pkg.DependencyType(), pkg.Dir)
+		}
+		return w.Flush()
+	default:
+		for _, pkg := range packages {
+			fmt.Println(util.JoinVersion(pkg.Path, pkg.Version))
+		}
+		return nil
+	}
+}
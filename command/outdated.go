@@ -0,0 +1,99 @@
+package command
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+)
+
+func outdated() *cobra.Command {
+	var directOnly bool
+	var interactive bool
+
+	outdatedCmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "List the dependencies that have a newer version",
+		Long: "List the dependencies that have a newer version available, with the kind of bump (major, minor, patch) and whether they are direct or indirect dependencies.\n" +
+			"Deprecated dependencies are listed with their deprecation message, even at their latest version.",
+		Example: "gopack outdated\ngopack outdated --direct\ngopack outdated -i",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			packages, err := util.GetOutdatedDependencies(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("error getting outdated dependencies: %v", err)
+			}
+
+			if directOnly {
+				packages = util.DirectDependencies(packages)
+			}
+
+			// direct dependencies first, they are the ones you upgrade by hand
+			util.SortDependencies(packages)
+
+			if format == outputJSON {
+				if packages == nil {
+					packages = []util.Package{}
+				}
+				return printJSON(packages)
+			}
+
+			if len(packages) == 0 {
+				fmt.Println("All dependencies are up to date")
+				return nil
+			}
+
+			if format == outputPlain {
+				for _, pkg := range packages {
+					available, _ := availableColumns(pkg)
+					fmt.Printf("%s %s %s\n", pkg.Path, pkg.Version, available)
+				}
+				return nil
+			}
+
+			if interactive {
+				p := tea.NewProgram(tui.NewOutdatedModel(packages), tea.WithAltScreen(), tea.WithContext(cmd.Context()))
+				_, err := p.Run()
+				return err
+			}
+
+			printOutdated(packages)
+			return nil
+		},
+	}
+
+	outdatedCmd.Flags().BoolVarP(&directOnly, "direct", "d", false, "Only show direct dependencies")
+	outdatedCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Show the report in an interactive table")
+
+	return outdatedCmd
+}
+
+func printOutdated(packages []util.Package) {
+	w := newTableWriter()
+	fmt.Fprintln(w, "MODULE\tCURRENT\tAVAILABLE\tBUMP\tTYPE")
+	for _, pkg := range packages {
+		available, bump := availableColumns(pkg)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pkg.Path, pkg.Version, available, bump, pkg.DependencyType())
+	}
+	w.Flush()
+
+	for _, pkg := range packages {
+		if pkg.Deprecated != "" {
+			fmt.Printf("\n%s is deprecated: %s\n", pkg.Path, pkg.Deprecated)
+		}
+	}
+}
+
+// availableColumns returns the available version and the bump, "-" for a deprecated module without an update.
+func availableColumns(pkg util.Package) (available string, bump string) {
+	if pkg.Update == nil {
+		return "-", "-"
+	}
+	return pkg.Update.Version, util.Bump(pkg.Version, pkg.Update.Version)
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputPlain = "plain"
+)
+
+// outputFormat returns the value of the global --output flag, empty when it was not set.
+func outputFormat(cmd *cobra.Command) (string, error) {
+	f := cmd.Flag("output")
+	if f == nil {
+		return "", nil
+	}
+
+	switch format := f.Value.String(); format {
+	case "", outputJSON, outputTable, outputPlain:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format: %s (expected json, table or plain)", format)
+	}
+}
+
+// printJSON writes v to stdout as indented JSON.
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// newTableWriter returns a writer that aligns tab separated columns on stdout, call Flush when done.
+func newTableWriter() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+)
+
+func remove() *cobra.Command {
+	var skipConfirm bool
+
+	removeCmd := &cobra.Command{
+		Use:     "remove [module...]",
+		Aliases: []string{"rm"},
+		Short:   "Remove dependencies from the module",
+		Long:    "Remove dependencies with 'go get MODULE@none' followed by 'go mod tidy'. Without arguments, pick the direct dependencies to remove from a list.",
+		Example: "gopack remove github.com/go-chi/chi/v5\ngopack remove",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modules := make([]string, len(args))
+			for i, arg := range args {
+				modules[i], _ = util.SplitQuery(arg)
+			}
+
+			if len(modules) == 0 {
+				selected, err := selectModulesToRemove(cmd.Context())
+				if err != nil {
+					return err
+				}
+				modules = selected
+			}
+
+			if len(modules) == 0 {
+				fmt.Println("Nothing to remove")
+				return nil
+			}
+
+			// warn about the packages that still import the modules, go mod tidy would add them back
+			for _, module := range modules {
+				importers, err := util.FindImporters(cmd.Context(), module)
+				if err != nil {
+					log.Warn("could not check the importers", "module", module, "err", err)
+					continue
+				}
+				if len(importers) > 0 {
+					fmt.Printf("%s is still imported by:\n  %s\n", module, strings.Join(importers, "\n  "))
+				}
+			}
+
+			if !skipConfirm {
+				fmt.Printf("Remove %s? [y/N] ", strings.Join(modules, ", "))
+				var confirmation string
+				fmt.Scanln(&confirmation)
+
+				if strings.ToLower(confirmation) != "y" {
+					fmt.Println("Remove cancelled.")
+					return nil
+				}
+			}
+
+			for _, module := range modules {
+				if err := util.RemoveModule(cmd.Context(), module); err != nil {
+					return fmt.Errorf("error removing %s: %v", module, err)
+				}
+				fmt.Println("Removed", module)
+			}
+
+			if err := util.RunGoModTidy(cmd.Context()); err != nil {
+				return fmt.Errorf("error running go mod tidy: %v", err)
+			}
+
+			return nil
+		},
+	}
+
+	removeCmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Remove without asking for confirmation")
+
+	return removeCmd
+}
+
+func selectModulesToRemove(ctx context.Context) ([]string, error) {
+	packages, err := util.GetDependencyList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting dependency list: %v", err)
+	}
+
+	direct := util.DirectDependencies(packages)
+	if len(direct) == 0 {
+		return nil, nil
+	}
+
+	m := tui.NewRemoveModel(direct)
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx))
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	return tui.SelectedModules(final), nil
+}
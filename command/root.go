@@ -3,8 +3,14 @@ package command
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
 	"github.com/spf13/cobra"
 )
 
@@ -31,9 +37,13 @@ func Execute() error {
 				return nil
 			}
 
-			// If no arguments, show help
+			// If no arguments, start the interactive search
 			if len(args) == 0 {
-				return cmd.Help()
+				searcher, err := newSearcher(cmd)
+				if err != nil {
+					return err
+				}
+				return runInteractiveSearch(cmd.Context(), searcher, isOffline(cmd))
 			}
 
 			// Check if the first argument is a known subcommand
@@ -66,11 +76,55 @@ func Execute() error {
 	// Add global version flag
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Print version information")
 
+	// Add global search flags
+	rootCmd.PersistentFlags().String("source", "", "Search backend to use (pkg.go.dev, index, offline)")
+	rootCmd.PersistentFlags().String("source-url", "", "Override the url of the search backend")
+	rootCmd.PersistentFlags().Bool("refresh", false, "Search again instead of using cached results")
+	rootCmd.PersistentFlags().Bool("offline", false, "Search and install from the local module cache without reaching the network")
+
+	// Add global output flag
+	rootCmd.PersistentFlags().StringP("output", "o", "", "Print machine readable output instead of the TUI (json, table, plain)")
+
 	rootCmd.AddCommand(get())
+	rootCmd.AddCommand(install())
 	rootCmd.AddCommand(run())
 	rootCmd.AddCommand(list())
+	rootCmd.AddCommand(remove())
+	rootCmd.AddCommand(outdated())
+	rootCmd.AddCommand(upgrade())
 	rootCmd.AddCommand(update())
+	rootCmd.AddCommand(cache())
+	rootCmd.AddCommand(history())
+	rootCmd.AddCommand(fav())
 	rootCmd.AddCommand(versionCmd())
 
-	return rootCmd.ExecuteContext(context.Background())
+	// ctrl+c and kill cancel the searches and go commands in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// a second ctrl+c kills gop right away
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		// whatever failed, it failed because the user stopped it
+		return ExitError{Code: ExitInterrupted, Err: err}
+	}
+	return err
+}
+
+func runInteractiveSearch(ctx context.Context, searcher util.Searcher, offline bool) error {
+	m := tui.NewSearchModel(ctx, searcher)
+	m.SetOffline(offline)
+	m.SetRecentQueries(recentQueries())
+	m.SetFavorites(favoriteQueries())
+	p := tea.NewProgram(m, tea.WithContext(ctx))
+	final, err := p.Run()
+	if err != nil {
+		return fmt.Errorf("Alas, there's been an error: %v", err)
+	}
+	recordHistory(historyGet, tui.Results(final))
+	return nil
 }
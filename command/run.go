@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/charmbracelet/log"
 	"github.com/juancwu/gopack/config"
@@ -32,7 +33,11 @@ func run() *cobra.Command {
 
 			// Handle list flag
 			if listScripts {
-				return handleListScripts(cfg)
+				format, err := outputFormat(cmd)
+				if err != nil {
+					return err
+				}
+				return handleListScripts(cfg, format)
 			}
 
 			// Handle running a script
@@ -62,8 +67,37 @@ func handleInitConfig() error {
 	return nil
 }
 
-func handleListScripts(cfg *config.Config) error {
+// scriptEntry is a script as printed by run --list with machine readable output
+type scriptEntry struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+func handleListScripts(cfg *config.Config, format string) error {
 	scripts := config.ListScripts(cfg)
+	sort.Strings(scripts)
+
+	switch format {
+	case outputJSON:
+		entries := make([]scriptEntry, len(scripts))
+		for i, script := range scripts {
+			entries[i] = scriptEntry{Name: script, Command: cfg.Scripts[script]}
+		}
+		return printJSON(entries)
+	case outputTable:
+		w := newTableWriter()
+		fmt.Fprintln(w, "NAME\tCOMMAND")
+		for _, script := range scripts {
+			fmt.Fprintf(w, "%s\t%s\n", script, cfg.Scripts[script])
+		}
+		return w.Flush()
+	case outputPlain:
+		for _, script := range scripts {
+			fmt.Printf("%s: %s\n", script, cfg.Scripts[script])
+		}
+		return nil
+	}
+
 	if len(scripts) == 0 {
 		fmt.Println("No scripts defined in configuration")
 		return nil
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/juancwu/gopack/config"
+	"github.com/spf13/cobra"
 )
 
 func TestRunCommand(t *testing.T) {
@@ -87,6 +88,38 @@ func TestRunCommand(t *testing.T) {
 		}
 	})
 
+	t.Run("List flag with json output", func(t *testing.T) {
+		// The output flag is global, so run the command under a parent that defines it
+		parent := &cobra.Command{Use: "gop"}
+		parent.PersistentFlags().StringP("output", "o", "", "")
+		parent.AddCommand(run())
+		parent.SetArgs([]string{"run", "--list", "--output", "json"})
+
+		// Capture stdout
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		// Run command
+		if err := parent.Execute(); err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+
+		// Restore stdout
+		w.Close()
+		os.Stdout = oldStdout
+
+		// Decode captured output
+		var entries []scriptEntry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			t.Fatalf("Failed to decode json output: %v", err)
+		}
+
+		if len(entries) != 1 || entries[0].Name != "hello" || entries[0].Command != "echo Hello World" {
+			t.Errorf("Expected script 'hello' with command 'echo Hello World', got: %+v", entries)
+		}
+	})
+
 	t.Run("No script specified", func(t *testing.T) {
 		// Get a fresh run command
 		cmd := run()
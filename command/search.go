@@ -0,0 +1,125 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// newSearcher builds the searcher selected with the --source and --source-url flags, see
+// newSourceSearcher. The starred packages and the packages in bundles are answered without searching.
+func newSearcher(cmd *cobra.Command) (util.Searcher, error) {
+	searcher, err := newSourceSearcher(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return withKnownPackages(searcher), nil
+}
+
+// withKnownPackages makes the searcher answer the import paths of the starred packages and
+// of the packages in bundles. Favorites and bundles that can't be read are left out.
+func withKnownPackages(searcher util.Searcher) util.Searcher {
+	var known []util.SearchResult
+	if bundles, err := config.LoadBundles(); err == nil {
+		for _, pkgs := range bundles {
+			for _, pkg := range pkgs {
+				path, version := util.SplitQuery(pkg)
+				if !semver.IsValid(version) {
+					// a query like latest is not a version of the result
+					version = ""
+				}
+				known = append(known, util.SearchResult{ImportPath: path, Version: version})
+			}
+		}
+	}
+	// favorites come last so their synopsis wins
+	if favorites, err := config.LoadFavorites(); err == nil {
+		for _, f := range favorites {
+			known = append(known, util.SearchResult{ImportPath: f.Path, Synopsis: f.Synopsis, Version: f.Version})
+		}
+	}
+
+	if len(known) == 0 {
+		return searcher
+	}
+	return util.NewKnownSearcher(searcher, known)
+}
+
+// newSourceSearcher builds the searcher selected with the --source and --source-url flags.
+// It falls back to the search section in gopack.json and then to pkg.go.dev.
+// Queries matching GOPRIVATE go to the privateIndex in gopack.json, or are taken as import paths.
+// The results are cached unless the cacheTTL in gopack.json is 0.
+func newSourceSearcher(cmd *cobra.Command) (util.Searcher, error) {
+	var source, sourceURL, cacheTTL, privateIndex string
+
+	if cfg, err := config.LoadConfig(""); err == nil && cfg.Search != nil {
+		source = cfg.Search.Source
+		sourceURL = cfg.Search.URL
+		cacheTTL = cfg.Search.CacheTTL
+		privateIndex = cfg.Search.PrivateIndex
+	}
+
+	if f := cmd.Flag("source"); f != nil && f.Changed {
+		source = f.Value.String()
+	}
+	if f := cmd.Flag("source-url"); f != nil && f.Changed {
+		sourceURL = f.Value.String()
+	}
+	if isOffline(cmd) {
+		source = util.SourceOffline
+	}
+
+	searcher, err := util.NewSearcher(source, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := util.DefaultCacheTTL
+	if cacheTTL != "" {
+		ttl, err = time.ParseDuration(cacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search cache ttl: %v", err)
+		}
+	}
+	// the module cache doesn't need another cache, and has the private modules already
+	if source == util.SourceOffline {
+		return searcher, nil
+	}
+
+	if env, err := util.LoadProxyEnv(cmd.Context()); err == nil && env.GOPRIVATE != "" {
+		var private util.Searcher
+		if privateIndex != "" {
+			private = util.NewIndexSearcher(privateIndex)
+		}
+		searcher = util.NewPrivateSearcher(searcher, private, env.GOPRIVATE)
+	}
+
+	if ttl <= 0 {
+		return searcher, nil
+	}
+
+	dir, err := config.CacheDir()
+	if err != nil {
+		// searching works without a cache
+		return searcher, nil
+	}
+
+	if source == "" {
+		source = util.SourcePkgGoDev
+	}
+	cached := util.NewCachingSearcher(searcher, source+" "+sourceURL+" "+privateIndex, dir, ttl)
+	if f := cmd.Flag("refresh"); f != nil {
+		cached.Refresh = f.Value.String() == "true"
+	}
+	return cached, nil
+}
+
+// isOffline reports whether the --offline flag was passed.
+func isOffline(cmd *cobra.Command) bool {
+	f := cmd.Flag("offline")
+	return f != nil && f.Value.String() == "true"
+}
@@ -0,0 +1,114 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/tui"
+	"github.com/juancwu/gopack/util"
+	"github.com/spf13/cobra"
+)
+
+func upgrade() *cobra.Command {
+	var runTests bool
+	var scriptName string
+	var directOnly bool
+
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Pick outdated dependencies to upgrade",
+		Long: "Pick the outdated dependencies to upgrade and the version of each one, then apply them with 'go get' and 'go mod tidy'.\n" +
+			"With --test the test script from gopack.json runs afterwards and go.mod and go.sum are rolled back if it fails.",
+		Example: "gopack upgrade\ngopack upgrade --test\ngopack upgrade --test --script check",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// load the config first so a missing script is reported before changing anything
+			var cfg *config.Config
+			if runTests {
+				var err error
+				cfg, err = config.LoadConfig("")
+				if err != nil {
+					return fmt.Errorf("failed to load configuration: %v", err)
+				}
+				if _, ok := cfg.Scripts[scriptName]; !ok {
+					return fmt.Errorf("script not found: %s", scriptName)
+				}
+			}
+
+			packages, err := util.GetOutdatedDependencies(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("error getting outdated dependencies: %v", err)
+			}
+			// deprecated modules at their latest version have nothing to upgrade to
+			packages = util.UpgradableDependencies(packages)
+			if directOnly {
+				packages = util.DirectDependencies(packages)
+			}
+			if len(packages) == 0 {
+				fmt.Println("All dependencies are up to date")
+				return nil
+			}
+			util.SortDependencies(packages)
+
+			p := tea.NewProgram(tui.NewUpgradeModel(cmd.Context(), packages), tea.WithAltScreen(), tea.WithContext(cmd.Context()))
+			final, err := p.Run()
+			if err != nil {
+				return err
+			}
+
+			upgrades := tui.SelectedUpgrades(final)
+			if len(upgrades) == 0 {
+				fmt.Println("Nothing to upgrade")
+				return nil
+			}
+
+			return applyUpgrades(cmd.Context(), upgrades, cfg, scriptName)
+		},
+	}
+
+	upgradeCmd.Flags().BoolVarP(&runTests, "test", "t", false, "Run the test script after upgrading and roll back if it fails")
+	upgradeCmd.Flags().StringVar(&scriptName, "script", "test", "Name of the script to run with --test")
+	upgradeCmd.Flags().BoolVarP(&directOnly, "direct", "d", false, "Only show direct dependencies")
+
+	return upgradeCmd
+}
+
+// applyUpgrades installs the module@version upgrades, rolling go.mod and go.sum back if anything fails.
+// The script only runs when cfg is not nil.
+func applyUpgrades(ctx context.Context, upgrades []string, cfg *config.Config, scriptName string) error {
+	restore, err := util.BackupModFiles()
+	if err != nil {
+		return err
+	}
+
+	rollback := func(reason error) error {
+		if err := restore(); err != nil {
+			log.Error("could not roll back go.mod and go.sum", "err", err)
+			return reason
+		}
+		fmt.Println("Rolled back go.mod and go.sum")
+		return reason
+	}
+
+	fmt.Printf("Upgrading %s...\n", strings.Join(upgrades, ", "))
+	if err := util.RunGoGet(ctx, upgrades...); err != nil {
+		return rollback(fmt.Errorf("error upgrading: %v", err))
+	}
+	if err := util.RunGoModTidy(ctx); err != nil {
+		return rollback(fmt.Errorf("error running go mod tidy: %v", err))
+	}
+
+	if cfg != nil {
+		fmt.Printf("Running script %s...\n", scriptName)
+		if err := config.RunScript(cfg, scriptName, nil); err != nil {
+			log.Error("script failed after upgrading", "name", scriptName)
+			return rollback(err)
+		}
+	}
+
+	fmt.Println("Upgraded", strings.Join(upgrades, ", "))
+	return nil
+}
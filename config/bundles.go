@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// GlobalConfigPath returns the path of the user wide gopack.json, its bundles can be used in every project
+func GlobalConfigPath() (string, error) {
+	dir, err := GlobalDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, DefaultConfigName), nil
+}
+
+// LoadBundles returns the bundles of the user wide gopack.json and of the gopack.json in the
+// current directory. A project bundle replaces the user wide bundle with the same name, so a
+// team can commit its bundles with the project. Missing files have no bundles.
+func LoadBundles() (map[string][]string, error) {
+	global, err := GlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := map[string][]string{}
+	for _, path := range []string{global, DefaultConfigName} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		for name, pkgs := range cfg.Bundles {
+			bundles[name] = pkgs
+		}
+	}
+	return bundles, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadBundles(t *testing.T) {
+	// Point the user config directory to a temporary directory
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	// No config files at all
+	bundles, err := LoadBundles()
+	if err != nil || len(bundles) != 0 {
+		t.Fatalf("Expected no bundles, got %v, %v", bundles, err)
+	}
+
+	global := `{"bundles": {"web": ["github.com/go-chi/chi/v5@latest"], "log": ["go.uber.org/zap@v1.26.0"]}}`
+	if err := os.MkdirAll(filepath.Join(configDir, GlobalDirName), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, GlobalDirName, DefaultConfigName), []byte(global), 0644); err != nil {
+		t.Fatalf("Failed to write global config: %v", err)
+	}
+	project := `{"scripts": {"test": "go test ./..."}, "bundles": {"web": ["github.com/labstack/echo/v4@v4.11.4"]}}`
+	if err := os.WriteFile(DefaultConfigName, []byte(project), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	bundles, err = LoadBundles()
+	if err != nil {
+		t.Fatalf("LoadBundles failed: %v", err)
+	}
+	expected := map[string][]string{
+		// the project bundle wins
+		"web": {"github.com/labstack/echo/v4@v4.11.4"},
+		"log": {"go.uber.org/zap@v1.26.0"},
+	}
+	if !reflect.DeepEqual(bundles, expected) {
+		t.Errorf("Expected %v, got %v", expected, bundles)
+	}
+}
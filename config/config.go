@@ -13,6 +13,24 @@ import (
 // Config represents the structure of the gopack.json configuration file
 type Config struct {
 	Scripts map[string]string `json:"scripts,omitempty"`
+	Search  *SearchConfig     `json:"search,omitempty"`
+	// Bundles maps a name to the packages installed together with 'gop get --bundle NAME',
+	// e.g. "web": ["github.com/go-chi/chi/v5@latest"]
+	Bundles map[string][]string `json:"bundles,omitempty"`
+	// Favorites are the starred packages, only read from the user wide gopack.json
+	Favorites []Favorite `json:"favorites,omitempty"`
+}
+
+// SearchConfig selects the backend used to search for packages
+type SearchConfig struct {
+	// Source is the name of the search backend, defaults to pkg.go.dev
+	Source string `json:"source,omitempty"`
+	// URL overrides the endpoint of the search backend
+	URL string `json:"url,omitempty"`
+	// CacheTTL is how long search results are cached, e.g. "12h". "0" disables the cache
+	CacheTTL string `json:"cacheTTL,omitempty"`
+	// PrivateIndex is the url of a JSON index searched for the queries matching GOPRIVATE
+	PrivateIndex string `json:"privateIndex,omitempty"`
 }
 
 type ScriptError struct {
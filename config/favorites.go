@@ -0,0 +1,124 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Favorite is a package starred to be installed with @fav without searching it
+type Favorite struct {
+	Path string `json:"path"`
+	// Version pins the version installed with @fav, the latest when empty
+	Version  string `json:"version,omitempty"`
+	Synopsis string `json:"synopsis,omitempty"`
+}
+
+// LoadFavorites returns the starred packages of the user wide gopack.json, an empty list if
+// none were starred yet
+func LoadFavorites() ([]Favorite, error) {
+	path, err := GlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []Favorite{}, nil
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Favorites == nil {
+		return []Favorite{}, nil
+	}
+	return cfg.Favorites, nil
+}
+
+// AddFavorite stars the package, replacing the version and synopsis if it was already starred
+func AddFavorite(favorite Favorite) error {
+	favorites, err := LoadFavorites()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range favorites {
+		if favorites[i].Path == favorite.Path {
+			favorites[i] = favorite
+			found = true
+			break
+		}
+	}
+	if !found {
+		favorites = append(favorites, favorite)
+	}
+
+	return saveFavorites(favorites)
+}
+
+// RemoveFavorite unstars the package, found is false when it was not starred
+func RemoveFavorite(path string) (found bool, err error) {
+	favorites, err := LoadFavorites()
+	if err != nil {
+		return false, err
+	}
+
+	kept := favorites[:0]
+	for _, favorite := range favorites {
+		if favorite.Path == path {
+			found = true
+			continue
+		}
+		kept = append(kept, favorite)
+	}
+	if !found {
+		return false, nil
+	}
+
+	return true, saveFavorites(kept)
+}
+
+// saveFavorites writes the favorites into the user wide gopack.json. The file is edited as
+// raw JSON so the keys this version of gopack doesn't know about are kept.
+func saveFavorites(favorites []Favorite) error {
+	path, err := GlobalConfigPath()
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("failed to parse config file: %v", err)
+		}
+	}
+
+	if len(favorites) == 0 {
+		delete(fields, "favorites")
+	} else {
+		encoded, err := json.Marshal(favorites)
+		if err != nil {
+			return fmt.Errorf("failed to encode favorites: %v", err)
+		}
+		fields["favorites"] = encoded
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	data, err = json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFavorites(t *testing.T) {
+	// Point the user config directory to a temporary directory
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	favorites, err := LoadFavorites()
+	if err != nil {
+		t.Fatalf("LoadFavorites failed: %v", err)
+	}
+	if len(favorites) != 0 {
+		t.Errorf("Expected no favorites, got %d", len(favorites))
+	}
+
+	if err := AddFavorite(Favorite{Path: "github.com/go-chi/chi/v5"}); err != nil {
+		t.Fatalf("AddFavorite failed: %v", err)
+	}
+	if err := AddFavorite(Favorite{Path: "go.uber.org/zap", Version: "v1.26.0"}); err != nil {
+		t.Fatalf("AddFavorite failed: %v", err)
+	}
+
+	// Starring a package again replaces it
+	if err := AddFavorite(Favorite{Path: "github.com/go-chi/chi/v5", Version: "v5.0.12"}); err != nil {
+		t.Fatalf("AddFavorite failed: %v", err)
+	}
+
+	favorites, err = LoadFavorites()
+	if err != nil {
+		t.Fatalf("LoadFavorites failed: %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Fatalf("Expected 2 favorites, got %d", len(favorites))
+	}
+	if favorites[0].Version != "v5.0.12" {
+		t.Errorf("Expected version v5.0.12, got %s", favorites[0].Version)
+	}
+
+	found, err := RemoveFavorite("github.com/go-chi/chi/v5")
+	if err != nil || !found {
+		t.Fatalf("Expected the favorite to be removed, got %v, %v", found, err)
+	}
+	found, err = RemoveFavorite("github.com/go-chi/chi/v5")
+	if err != nil || found {
+		t.Errorf("Expected nothing to remove, got %v, %v", found, err)
+	}
+
+	favorites, err = LoadFavorites()
+	if err != nil {
+		t.Fatalf("LoadFavorites failed: %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].Path != "go.uber.org/zap" {
+		t.Errorf("Expected only go.uber.org/zap, got %v", favorites)
+	}
+}
+
+func TestFavoritesKeepGlobalConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := GlobalConfigPath()
+	if err != nil {
+		t.Fatalf("GlobalConfigPath failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	global := `{"bundles": {"web": ["github.com/go-chi/chi/v5"]}, "editor": "vim"}`
+	if err := os.WriteFile(path, []byte(global), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddFavorite(Favorite{Path: "go.uber.org/zap"}); err != nil {
+		t.Fatalf("AddFavorite failed: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Favorites, []Favorite{{Path: "go.uber.org/zap"}}) {
+		t.Errorf("Expected go.uber.org/zap to be starred, got %v", cfg.Favorites)
+	}
+	if !reflect.DeepEqual(cfg.Bundles, map[string][]string{"web": {"github.com/go-chi/chi/v5"}}) {
+		t.Errorf("Expected the bundles to be kept, got %v", cfg.Bundles)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil || fields["editor"] != "vim" {
+		t.Errorf("Expected the unknown keys to be kept, got %s", data)
+	}
+}
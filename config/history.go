@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// HistoryFileName is the name of the file in the data directory that records the searches
+// and installs, one JSON object per line
+const HistoryFileName = "history.jsonl"
+
+// HistoryEntry records the outcome of a query searched with gop get, gop install or the interactive mode
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	// Command is "get" or "install", install entries are binaries installed with go install
+	Command string `json:"command"`
+	Query   string `json:"query"`
+	// Path and Version are the package picked for the query, empty when nothing was picked
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Dir is the project directory the package was added to
+	Dir    string `json:"dir,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// DataDir returns the directory that holds the user wide gopack data, e.g. the history.
+// It follows XDG_DATA_HOME on unix systems.
+func DataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, GlobalDirName), nil
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return filepath.Join(dir, GlobalDirName), nil
+		}
+		return "", fmt.Errorf("failed to find user data directory: %%LocalAppData%% is not set")
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find user data directory: %v", err)
+		}
+		return filepath.Join(home, "Library", "Application Support", GlobalDirName), nil
+	default:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find user data directory: %v", err)
+		}
+		return filepath.Join(home, ".local", "share", GlobalDirName), nil
+	}
+}
+
+// AppendHistory adds the entries to the end of the history file.
+func AppendHistory(entries ...HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer f.Close()
+
+	// a single write per entry keeps the lines whole when two gop run at the same time
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %v", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write history file: %v", err)
+		}
+	}
+	return nil
+}
+
+// LoadHistory returns the recorded entries, oldest first. Lines that can't be parsed are skipped.
+func LoadHistory() ([]HistoryEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+	defer f.Close()
+
+	// a bufio.Reader rather than a bufio.Scanner, the error of a failed go get can make a
+	// line longer than the limit of a Scanner
+	entries := []HistoryEntry{}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			var entry HistoryEntry
+			if json.Unmarshal(line, &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history file: %v", err)
+		}
+	}
+}
+
+// ClearHistory removes the history file.
+func ClearHistory() error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove history file: %v", err)
+	}
+	return nil
+}
+
+// RecentQueries returns the distinct queries of the history, most recent first.
+func RecentQueries(entries []HistoryEntry) []string {
+	seen := map[string]bool{}
+	var queries []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		query := entries[i].Query
+		if query == "" || seen[query] {
+			continue
+		}
+		seen[query] = true
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+func historyPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, HistoryFileName), nil
+}
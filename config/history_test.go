@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	// Point the user data directory to a temporary directory
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+
+	entries, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries, got %d", len(entries))
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	recorded := []HistoryEntry{
+		{Time: now, Command: "get", Query: "chi", Path: "github.com/go-chi/chi/v5", Version: "v5.0.12", Dir: "/src/app", Status: "ok"},
+		{Time: now, Command: "get", Query: "nothing", Status: "no_match", Error: "No packages found"},
+		// longer than the 64 KiB line limit of a bufio.Scanner
+		{Time: now, Command: "get", Query: "broken", Path: "example.com/broken", Status: "failed", Error: strings.Repeat("x", 100<<10)},
+	}
+	if err := AppendHistory(recorded[0]); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+	if err := AppendHistory(recorded[1:]...); err != nil {
+		t.Fatalf("AppendHistory failed: %v", err)
+	}
+
+	// a broken line doesn't lose the rest of the history
+	path := filepath.Join(dataDir, GlobalDirName, HistoryFileName)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open history file: %v", err)
+	}
+	f.WriteString("{broken\n")
+	f.Close()
+
+	entries, err = LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if !reflect.DeepEqual(entries, recorded) {
+		t.Errorf("Expected %+v, got %+v", recorded, entries)
+	}
+
+	if err := ClearHistory(); err != nil {
+		t.Fatalf("ClearHistory failed: %v", err)
+	}
+	entries, err = LoadHistory()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected an empty history, got %v, %v", entries, err)
+	}
+}
+
+func TestRecentQueries(t *testing.T) {
+	entries := []HistoryEntry{{Query: "chi"}, {Query: "cors"}, {Query: "chi"}, {Query: ""}, {Query: "zap"}}
+	expected := []string{"zap", "chi", "cors"}
+	if got := RecentQueries(entries); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
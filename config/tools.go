@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// GlobalDirName is the name of the gopack directory inside the user config directory
+	GlobalDirName = "gopack"
+	// ToolsFileName is the name of the file that records the tools installed with gop install
+	ToolsFileName = "tools.json"
+)
+
+// Tool is a binary installed with gop install
+type Tool struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// GlobalDir returns the directory that holds the user wide gopack files
+func GlobalDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user config directory: %v", err)
+	}
+	return filepath.Join(dir, GlobalDirName), nil
+}
+
+// CacheDir returns the directory that holds the user wide gopack cache
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %v", err)
+	}
+	return filepath.Join(dir, GlobalDirName), nil
+}
+
+// LoadTools returns the recorded tools, an empty list if none were recorded yet
+func LoadTools() ([]Tool, error) {
+	path, err := toolsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []Tool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tools file: %v", err)
+	}
+
+	var tools []Tool
+	if err := json.Unmarshal(data, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse tools file: %v", err)
+	}
+
+	return tools, nil
+}
+
+// RecordTool adds the tool to the recorded tools, replacing the version if it was already recorded
+func RecordTool(tool Tool) error {
+	tools, err := LoadTools()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range tools {
+		if tools[i].Path == tool.Path {
+			tools[i].Version = tool.Version
+			found = true
+			break
+		}
+	}
+	if !found {
+		tools = append(tools, tool)
+	}
+
+	return saveTools(tools)
+}
+
+func saveTools(tools []Tool) error {
+	path, err := toolsPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(tools, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tools: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tools file: %v", err)
+	}
+
+	return nil
+}
+
+func toolsPath() (string, error) {
+	dir, err := GlobalDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ToolsFileName), nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestRecordTool(t *testing.T) {
+	// Point the user config directory to a temporary directory
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// No tools recorded yet
+	tools, err := LoadTools()
+	if err != nil {
+		t.Fatalf("LoadTools failed: %v", err)
+	}
+	if len(tools) != 0 {
+		t.Errorf("Expected no tools, got %d", len(tools))
+	}
+
+	// Record two tools
+	if err := RecordTool(Tool{Path: "golang.org/x/tools/cmd/stringer", Version: "v0.20.0"}); err != nil {
+		t.Fatalf("RecordTool failed: %v", err)
+	}
+	if err := RecordTool(Tool{Path: "github.com/golangci/golangci-lint/cmd/golangci-lint", Version: "v1.57.2"}); err != nil {
+		t.Fatalf("RecordTool failed: %v", err)
+	}
+
+	// Recording an existing tool updates its version
+	if err := RecordTool(Tool{Path: "golang.org/x/tools/cmd/stringer", Version: "v0.21.0"}); err != nil {
+		t.Fatalf("RecordTool failed: %v", err)
+	}
+
+	tools, err = LoadTools()
+	if err != nil {
+		t.Fatalf("LoadTools failed: %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(tools))
+	}
+	if tools[0].Version != "v0.21.0" {
+		t.Errorf("Expected stringer version 'v0.21.0', got '%s'", tools[0].Version)
+	}
+}
@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/juancwu/gopack/util"
+)
+
+const (
+	// detailsWidth is the width of the panel next to the search results
+	detailsWidth = 48
+	readmeHeight = 20
+)
+
+var (
+	detailsStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("241")).
+			Padding(0, 1).
+			Width(detailsWidth)
+	labelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Width(13)
+)
+
+var (
+	readmeKey = key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "readme"),
+	)
+	starKey = key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "star"),
+	)
+)
+
+// packageDetails is the state of the details of a search result, they are loaded
+// the first time the result is highlighted
+type packageDetails struct {
+	details util.PackageDetails
+	err     error
+	loading bool
+}
+
+type afterDetailsMsg struct {
+	importPath string
+	details    util.PackageDetails
+	err        error
+}
+
+// proxyClient builds the proxy client of the go environment the first time the details
+// or a README are loaded, a model shares it between its copies.
+type proxyClient struct {
+	once   sync.Once
+	client *util.ProxyClient
+	err    error
+}
+
+func (p *proxyClient) get(ctx context.Context) (*util.ProxyClient, error) {
+	p.once.Do(func() {
+		p.client, p.err = util.NewProxyClientFromEnv(ctx)
+	})
+	return p.client, p.err
+}
+
+// detailsCmd looks up the latest version and repository of the search result. Offline,
+// only the module cache is looked at.
+func detailsCmd(ctx context.Context, proxy *proxyClient, r util.SearchResult, offline bool) tea.Cmd {
+	return func() tea.Msg {
+		var client *util.ProxyClient
+		if !offline {
+			var err error
+			client, err = proxy.get(ctx)
+			if err != nil {
+				return afterDetailsMsg{importPath: r.ImportPath, details: util.PackageDetails{SearchResult: r}, err: err}
+			}
+		}
+		details, err := util.LoadDetails(ctx, client, "", r)
+		return afterDetailsMsg{importPath: r.ImportPath, details: details, err: err}
+	}
+}
+
+type afterReadmeMsg struct {
+	importPath string
+	readme     string
+	err        error
+}
+
+// readmeCmd reads the README of the module at version, from the module cache or the proxy.
+func readmeCmd(ctx context.Context, proxy *proxyClient, r util.SearchResult, version string, offline bool) tea.Cmd {
+	return func() tea.Msg {
+		var client *util.ProxyClient
+		if !offline {
+			var err error
+			client, err = proxy.get(ctx)
+			if err != nil {
+				return afterReadmeMsg{importPath: r.ImportPath, err: err}
+			}
+		}
+		readme, err := util.ModuleReadme(ctx, client, "", r.Module(), version)
+		return afterReadmeMsg{importPath: r.ImportPath, readme: readme, err: err}
+	}
+}
+
+// renderDetails renders the details panel of a search result.
+func renderDetails(d packageDetails, spinner string) string {
+	r := d.details
+	var builder strings.Builder
+	builder.WriteString(headingStyle.Render(r.ImportPath) + "\n")
+	if r.Synopsis != "" {
+		builder.WriteString(r.Synopsis + "\n")
+	}
+	builder.WriteString("\n")
+
+	field := func(label string, value string) {
+		if value != "" {
+			builder.WriteString(labelStyle.Render(label) + value + "\n")
+		}
+	}
+	field("Module", r.Module())
+
+	latest := r.LatestVersion
+	if latest == "" {
+		latest = r.Version
+	}
+	if d.loading {
+		latest = spinner + " loading"
+	}
+	field("Latest", latest)
+	field("License", r.License)
+	if r.ImportedBy > 0 {
+		field("Imported by", fmt.Sprintf("%d", r.ImportedBy))
+	}
+	if !r.LatestTime.IsZero() {
+		field("Published", r.LatestTime.Format("Jan 2, 2006"))
+	} else {
+		field("Published", r.Published)
+	}
+	field("Repository", r.Repository)
+
+	if d.err != nil {
+		builder.WriteString("\n" + warnText.Render("Could not load all the details: "+d.err.Error()))
+	}
+	return detailsStyle.Render(builder.String())
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/juancwu/gopack/config"
+	"github.com/juancwu/gopack/util"
+)
+
+const historyTitle = "Install Again"
+
+// historyItem is a package picked in a past search
+type historyItem struct {
+	entry config.HistoryEntry
+}
+
+func (i historyItem) Title() string {
+	title := util.JoinVersion(i.entry.Path, i.entry.Version)
+	if i.entry.Command == "install" {
+		title += " (binary)"
+	}
+	return title
+}
+func (i historyItem) Description() string {
+	description := fmt.Sprintf("'%s' on %s", i.entry.Query, i.entry.Time.Local().Format(time.DateTime))
+	if i.entry.Dir != "" {
+		description += " in " + i.entry.Dir
+	}
+	return description
+}
+func (i historyItem) FilterValue() string { return i.entry.Path }
+
+// historyModel lets the user pick past installs to install again
+type historyModel struct {
+	List      list.Model
+	confirmed bool
+}
+
+func NewHistoryModel(entries []config.HistoryEntry) historyModel {
+	items := make([]list.DefaultItem, len(entries))
+	for i, entry := range entries {
+		items[i] = historyItem{entry: entry}
+	}
+
+	l := list.New(newCheckItems(items), list.NewDefaultDelegate(), 0, 0)
+	l.Title = selectionTitle(historyTitle, l)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{toggleKey, confirmKey}
+	}
+
+	return historyModel{
+		List: l,
+	}
+}
+
+func (m historyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// let the filter input have all the keys while filtering
+		if m.List.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case msg.String() == "ctrl+c":
+			return m, tea.Quit
+		case key.Matches(msg, toggleKey):
+			toggleSelected(&m.List)
+			m.List.Title = selectionTitle(historyTitle, m.List)
+			return m, nil
+		case key.Matches(msg, confirmKey):
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.List.SetSize(msg.Width-h, msg.Height-v)
+	}
+
+	var cmd tea.Cmd
+	m.List, cmd = m.List.Update(msg)
+	return m, cmd
+}
+
+func (m historyModel) View() string {
+	return docStyle.Render(m.List.View())
+}
+
+// SelectedHistory returns the entries checked in a confirmed history model.
+func SelectedHistory(model tea.Model) []config.HistoryEntry {
+	m, ok := model.(historyModel)
+	if !ok || !m.confirmed {
+		return nil
+	}
+	var entries []config.HistoryEntry
+	for _, item := range checkedItems(m.List) {
+		if h, ok := item.(historyItem); ok {
+			entries = append(entries, h.entry)
+		}
+	}
+	return entries
+}
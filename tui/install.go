@@ -1,57 +1,159 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/juancwu/gopack/config"
 	"github.com/juancwu/gopack/util"
 )
 
 type installModel struct {
-	spinner             spinner.Model
-	list                list.Model
-	isSearching         bool
-	isInstalling        bool
-	selectFirst         bool
-	queries             []string
-	results             []list.Item
+	// ctx is cancelled when the user quits, killing the searches and go commands in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+	// isCancelling is true while waiting for the cancelled go command to exit before quitting
+	isCancelling      bool
+	quitKey           string
+	spinner           spinner.Model
+	list              list.Model
+	isSearching       bool
+	isInstalling      bool
+	isListingVersions bool
+	isPickingVersion  bool
+	selectFirst       bool
+	queries           []string
+	// searches holds the outcome of the search of every query, they all run when the model starts
+	searches    []querySearch
+	searchSlots chan struct{}
+	// pending are the packages picked so far, installed together once every query is handled
+	pending []pendingInstall
+	results []list.Item
+	// resultsTitle is the title of the search results without the selection count
+	resultsTitle        string
 	searchingTerm       string
 	installingTerm      string
 	installationHistory []installResult
-	current_query_idx   int
-	err                 error
-	isDone              bool
+	// warnings are shown under the history, e.g. when cached results were used
+	warnings          []string
+	current_query_idx int
+	searcher          util.Searcher
+	// queryVersion is the version pinned in the current query with query@version
+	queryVersion string
+	// selected is the search result chosen for the current query
+	selected util.SearchResult
+	// picked is the index of the result of the first query picked before the model started, -1 for none
+	picked int
+	// searchErr is the error of the last search, the model waits for a retry or skip while it is set
+	searchErr error
+	err       error
+	isDone    bool
 	// name is the model name
 	name string
 	// asComponent represnets if the model is being used as part of a component to a parent model
 	asComponent bool
+	// goInstall makes the model run 'go install' to install binaries instead of 'go get'
+	goInstall bool
+	// offline lists versions from the module cache and runs the go commands with util.OfflineEnv
+	offline bool
+	// output shows what the go command writes while installing
+	output      viewport.Model
+	outputLines []string
+	outputCh    chan string
+	// isReviewing is true when the model finished with failures and lets the user read their output
+	isReviewing bool
+	// cursor is the highlighted history entry while reviewing
+	cursor int
+	// expanded is the history entry whose output is shown, -1 for none
+	expanded int
+	// details holds the details of the search results highlighted so far, by import path
+	details map[string]packageDetails
+	// proxy looks up the details and READMEs
+	proxy *proxyClient
+	// readme shows the README of the highlighted search result, readmeFor is its import path
+	readme          viewport.Model
+	readmeFor       string
+	isLoadingReadme bool
+	isReadingReadme bool
+}
+
+// querySearch is the state of the search of a query
+type querySearch struct {
+	results []list.Item
+	err     error
+	// warning is set when the results came from an expired cache
+	warning string
+	done    bool
+}
+
+// pendingInstall is a package picked for a query that is waiting to be installed
+type pendingInstall struct {
+	// query is the index of the query the package was picked for
+	query  int
+	pkg    string
+	module string
 }
 
 type installResult struct {
 	title   string
 	success bool
+	result  util.InstallResult
+	// log is everything the go command wrote while installing
+	log string
 }
 
 func (s installResult) Title() string { return s.title }
 
 // searchResult represents a single search result
-type searchResult string
+type searchResult struct {
+	result util.SearchResult
+	// starred is true when the package is a favorite
+	starred bool
+}
 
 // Implementation of list.DefaultItem and list.Item interfaces for SearchResult
-func (s searchResult) Title() string       { return string(s) }
-func (s searchResult) Description() string { return "" }
-func (s searchResult) FilterValue() string { return "" }
+func (s searchResult) Title() string {
+	if s.starred {
+		return "★ " + s.result.ImportPath
+	}
+	return s.result.ImportPath
+}
+func (s searchResult) Description() string {
+	if s.result.Version == "" {
+		return s.result.Synopsis
+	}
+	return fmt.Sprintf("%s | %s", s.result.Version, s.result.Synopsis)
+}
+func (s searchResult) FilterValue() string { return s.result.ImportPath }
+
+// versionItem represents a version of the selected module
+type versionItem string
+
+func (v versionItem) Title() string       { return string(v) }
+func (v versionItem) Description() string { return "" }
+func (v versionItem) FilterValue() string { return string(v) }
+
+func NewInstallModel(ctx context.Context, queries []string, selectFirst bool, searcher util.Searcher) installModel {
+	ctx, cancel := context.WithCancel(ctx)
 
-func NewInstallModel(queries []string, selectFirst bool) installModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
+	_, version := util.SplitQuery(queries[0])
+
 	return installModel{
+		ctx:                 ctx,
+		cancel:              cancel,
 		spinner:             s,
 		list:                list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
 		isSearching:         true,
@@ -61,57 +163,140 @@ func NewInstallModel(queries []string, selectFirst bool) installModel {
 		installationHistory: []installResult{},
 		selectFirst:         selectFirst,
 		queries:             queries,
+		searches:            make([]querySearch, len(queries)),
+		searchSlots:         make(chan struct{}, util.MaxConcurrentSearches),
 		current_query_idx:   0,
+		searcher:            searcher,
+		queryVersion:        version,
 		err:                 nil,
 		isDone:              false,
 		name:                "Install Model",
+		output:              viewport.New(outputWidth, outputHeight),
+		expanded:            -1,
+		picked:              -1,
+		details:             map[string]packageDetails{},
+		proxy:               &proxyClient{},
+		readme:              viewport.New(outputWidth, readmeHeight),
 	}
 }
 
 func (m installModel) Init() tea.Cmd {
-	// start first search here
-	return tea.Batch(
-		m.spinner.Tick,
-		searchCmd(m.queries[m.current_query_idx]),
-	)
+	// search every query up front, the selections are shown one after another as they finish
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for i, search := range m.searches {
+		if search.done {
+			// set with SetResults
+			msg := afterSearchMsg{query: i, results: search.results}
+			cmds = append(cmds, func() tea.Msg { return msg })
+			continue
+		}
+		cmds = append(cmds, m.searchCmd(i))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m installModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.isReviewing {
+			if model, cmd, handled := m.updateReview(msg); handled {
+				return model, cmd
+			}
+		}
+		if m.isLoadingReadme || m.isReadingReadme {
+			return m.updateReadme(msg)
+		}
 		switch msg.String() {
 		case "enter":
-			if !m.isSearching {
+			if m.isPickingVersion {
+				return m.installVersion()
+			}
+			// enter while installing would queue the first result again
+			if m.isShowingResults() {
 				return m.install()
 			}
-		case "ctrl+c": // force quit
-			if m.asComponent {
-				return m, quitCmd(msg.String(), m.name)
+		case "r":
+			if m.searchErr != nil {
+				return m.retrySearch()
+			}
+		case "n":
+			if m.searchErr != nil {
+				return m.skipSearch()
+			}
+		case "o":
+			if m.isShowingResults() {
+				return m.openReadme()
 			}
-			return m, tea.Quit
+		case "s":
+			if m.isShowingResults() {
+				return m.toggleStar()
+			}
+		case " ":
+			if m.isShowingResults() {
+				toggleSelected(&m.list)
+				m.list.Title = selectionTitle(m.resultsTitle, m.list)
+				return m, nil
+			}
+		case "ctrl+c": // force quit
+			return m.recordCancelled(m.current_query_idx).quit(msg.String())
 		case "q", "esc": // normal quit
-			if !m.isSearching || !m.isInstalling {
-				if m.asComponent {
-					return m, quitCmd(msg.String(), m.name)
-				}
-				return m, tea.Quit
+			first := m.current_query_idx
+			if m.searchErr != nil {
+				// quitting gives up on the failed search
+				m = m.recordSearchError()
+				first++
 			}
+			return m.recordCancelled(first).quit(msg.String())
 		default:
+			if m.isInstalling {
+				// scroll the output
+				m.output, cmd = m.output.Update(msg)
+				return m, cmd
+			}
 			m.list, cmd = m.list.Update(msg)
+			if m.isShowingResults() {
+				// the highlighted result may have changed
+				var loadCmd tea.Cmd
+				m, loadCmd = m.loadDetails()
+				return m, tea.Batch(cmd, loadCmd)
+			}
 			return m, cmd
 		}
+	case afterDetailsMsg:
+		m.details[msg.importPath] = packageDetails{details: msg.details, err: msg.err}
+		return m, nil
+	case afterReadmeMsg:
+		return m.showReadme(msg)
+	case outputLineMsg:
+		// ignore lines of an install that already finished
+		if msg.ch != m.outputCh {
+			return m, nil
+		}
+		m.outputLines = append(m.outputLines, msg.line)
+		m.output.SetContent(strings.Join(m.outputLines, "\n"))
+		m.output.GotoBottom()
+		return m, waitForOutput(msg.ch)
 	case afterSearchMsg:
-		m.results = msg.results
-		if m.selectFirst && len(msg.results) > 0 {
-			return m.install()
-		} else {
-			return m.showSearchResults()
+		m.searches[msg.query] = querySearch{results: msg.results, err: msg.err, warning: msg.warning, done: true}
+		// the other searches wait until their query comes up
+		if msg.query == m.current_query_idx && m.isSearching {
+			return m.handleSearch()
 		}
+		return m, nil
+	case afterVersionsMsg:
+		return m.showVersions(msg)
 	case afterInstallMsg:
-		m = m.recordHistory(msg.Err)
-		// search the next query
-		return m.search()
+		for i, p := range msg.Pending {
+			m = m.recordHistory(p, msg.Outcomes[i], msg.Log)
+		}
+		m.pending = nil
+		if m.isCancelling {
+			// the go command is gone, now it is safe to quit
+			m.isInstalling = false
+			return m.quit(m.quitKey)
+		}
+		return m.end(nil)
 	default:
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
@@ -130,19 +315,51 @@ func (m installModel) View() string {
 		builder.WriteString("\n")
 	}
 
+	if m.isReviewing {
+		return wrapper.Render(m.renderReview())
+	}
+
 	if m.isDone {
 		builder.WriteString("Done!\n")
 		return wrapper.Render(builder.String())
 	}
 
+	if m.isCancelling {
+		builder.WriteString(m.spinner.View() + " Cancelling...\n")
+		return wrapper.Render(builder.String())
+	}
+
+	if m.searchErr != nil {
+		builder.WriteString(errText.Render(fmt.Sprintf("Search for '%s' failed: %s", m.searchingTerm, m.searchErr.Error())) + "\n")
+		builder.WriteString(helpText.Render("r: retry • n: skip • q: quit") + "\n")
+		return wrapper.Render(builder.String())
+	}
 	if m.isSearching {
-		builder.WriteString(m.spinner.View() + fmt.Sprintf(" Searching '%s'\n", m.searchingTerm))
+		builder.WriteString(m.spinner.View() + fmt.Sprintf(" Searching '%s' (%d of %d searches done)\n", m.searchingTerm, m.searchesDone(), len(m.queries)))
+	}
+	if m.isListingVersions {
+		builder.WriteString(m.spinner.View() + fmt.Sprintf(" Fetching versions of '%s'\n", m.selected.Module()))
 	}
 	if m.isInstalling {
 		builder.WriteString(m.spinner.View() + fmt.Sprintf(" Installing '%s'\n", m.installingTerm))
+		if len(m.outputLines) > 0 {
+			builder.WriteString(outputStyle.Render(m.output.View()) + "\n")
+		}
+	}
+	if m.isLoadingReadme {
+		builder.WriteString(m.spinner.View() + fmt.Sprintf(" Loading the README of '%s'\n", m.readmeFor))
+		return wrapper.Render(builder.String())
+	}
+	if m.isReadingReadme {
+		builder.WriteString(headingStyle.Render("README of "+m.readmeFor) + "\n\n")
+		builder.WriteString(m.readme.View() + "\n\n")
+		builder.WriteString(helpText.Render("↑/↓: scroll • o/esc: back to the results") + "\n")
+		return wrapper.Render(builder.String())
 	}
-	if !m.isSearching && !m.isInstalling {
-		// show search result
+	if m.isShowingResults() {
+		// show search result next to the details of the highlighted one
+		builder.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), "  ", m.renderSelectedDetails()))
+	} else if !m.isSearching && !m.isListingVersions && !m.isInstalling {
 		builder.WriteString(m.list.View())
 	}
 
@@ -157,46 +374,437 @@ func (m *installModel) SetAsComponent(enabled bool) {
 	m.asComponent = enabled
 }
 
+// SetGoInstall makes the model install binaries with 'go install' instead of adding dependencies with 'go get'.
+func (m *installModel) SetGoInstall(enabled bool) {
+	m.goInstall = enabled
+}
+
+// SetResults makes the model use results for the first query instead of searching it. The
+// result at index picked is installed right away, or all are shown when picked is -1.
+func (m *installModel) SetResults(results []util.SearchResult, picked int) {
+	items := make([]list.Item, len(results))
+	for i, res := range results {
+		items[i] = searchResult{result: res}
+	}
+	m.searches[0] = querySearch{results: items, done: true}
+	m.picked = picked
+}
+
+// SetOffline makes the model install from the module cache without reaching the network.
+func (m *installModel) SetOffline(enabled bool) {
+	m.offline = enabled
+}
+
+// runner returns the GoRunner for the install commands.
+func (m installModel) runner() util.GoRunner {
+	return util.NewGoRunner(m.offline)
+}
+
+// Results returns the outcome of every query handled by a finished install model, or by
+// every install model of a finished search model.
+func Results(model tea.Model) []util.InstallResult {
+	if s, ok := model.(searchModel); ok {
+		if s.state == searchingState {
+			// quit with ctrl+c in the middle of an install
+			return append(s.installed, Results(s.im)...)
+		}
+		return s.installed
+	}
+	m, ok := model.(installModel)
+	if !ok {
+		return nil
+	}
+	results := make([]util.InstallResult, len(m.installationHistory))
+	for i, record := range m.installationHistory {
+		results[i] = record.result
+	}
+	return results
+}
+
 func (m installModel) install() (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
 	var item list.Item
 
 	if m.selectFirst {
 		item = m.results[0]
 	} else {
+		if checked := checkedItems(m.list); len(checked) > 0 {
+			return m.installChecked(checked)
+		}
 		item = m.list.SelectedItem()
 	}
 
-	if s, ok := item.(searchResult); ok {
-		title := string(s)
-		cmd = m.installCmd(title)
-		m.searchingTerm = ""
-		m.installingTerm = title
-		m.isSearching = false
-		m.isInstalling = true
+	s, ok := unwrapResult(item)
+	if !ok {
+		return m, nil
+	}
+	m.selected = s.result
+
+	// let the user pick a version unless the query already pinned one
+	if !m.selectFirst && m.queryVersion == "" {
+		m.isListingVersions = true
 		m.list = list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+		return m, tea.Batch(m.spinner.Tick, versionsCmd(m.ctx, m.selected.Module(), m.offline))
 	}
 
-	return m, cmd
+	version := m.queryVersion
+	if m.offline && version == "" {
+		// latest can't be resolved offline, take the cached version that was found
+		version = m.selected.Version
+	}
+	return m.startInstall(util.JoinVersion(m.selected.ImportPath, version))
+}
+
+func (m installModel) showVersions(msg afterVersionsMsg) (tea.Model, tea.Cmd) {
+	m.isListingVersions = false
+	m.isPickingVersion = true
+
+	var items []list.Item
+	if !m.offline {
+		items = append(items, versionItem("latest"))
+	}
+	for _, v := range msg.versions {
+		items = append(items, versionItem(v))
+	}
+
+	title := "Versions of " + m.selected.Module()
+	if msg.err != nil {
+		title += " (could not list versions: " + msg.err.Error() + ")"
+	}
+	m.list = newSelectList(items, title, false)
+
+	return m, nil
+}
+
+func (m installModel) installVersion() (tea.Model, tea.Cmd) {
+	v, ok := m.list.SelectedItem().(versionItem)
+	if !ok {
+		return m, nil
+	}
+	m.isPickingVersion = false
+	return m.startInstall(util.JoinVersion(m.selected.ImportPath, string(v)))
+}
+
+// installChecked queues every checked search result of the current query, at the version
+// pinned in the query, and moves on to the next query. There is no version to pick for each.
+func (m installModel) installChecked(items []list.DefaultItem) (tea.Model, tea.Cmd) {
+	for _, item := range items {
+		s, ok := item.(searchResult)
+		if !ok {
+			continue
+		}
+		version := m.queryVersion
+		if m.offline && version == "" {
+			version = s.result.Version
+		}
+		m = m.queue(s.result, util.JoinVersion(s.result.ImportPath, version))
+	}
+	return m.next()
+}
+
+// startInstall queues pkg for the current query and moves on to the next query.
+func (m installModel) startInstall(pkg string) (tea.Model, tea.Cmd) {
+	return m.queue(m.selected, pkg).next()
+}
+
+// queue adds pkg, picked from the search result r, to the packages installed after the last query.
+func (m installModel) queue(r util.SearchResult, pkg string) installModel {
+	// go install only accepts packages with a version
+	if _, version := util.SplitQuery(pkg); m.goInstall && version == "" {
+		pkg = util.JoinVersion(pkg, "latest")
+	}
+	m.pending = append(m.pending, pendingInstall{
+		query:  m.current_query_idx,
+		pkg:    pkg,
+		module: r.Module(),
+	})
+	return m
+}
+
+// installPending installs every picked package at once.
+func (m installModel) installPending() (tea.Model, tea.Cmd) {
+	if len(m.pending) == 0 {
+		return m.end(nil)
+	}
+
+	pkgs := make([]string, len(m.pending))
+	for i, p := range m.pending {
+		pkgs[i] = "'" + p.pkg + "'"
+	}
+	m.searchingTerm = ""
+	m.installingTerm = strings.Join(pkgs, ", ")
+	m.isSearching = false
+	m.isInstalling = true
+	m.list = list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+
+	// stream the output of the go command into the viewport
+	ch := make(chan string, 64)
+	m.outputCh = ch
+	m.outputLines = nil
+	m.output.SetContent("")
+
+	return m, tea.Batch(m.installCmd(ch), waitForOutput(ch))
+}
+
+// currentTerm returns the search term of the current query without its version.
+func (m installModel) currentTerm() string {
+	term, _ := util.SplitQuery(m.queries[m.current_query_idx])
+	return term
+}
+
+// searchesDone counts the queries whose search finished.
+func (m installModel) searchesDone() int {
+	done := 0
+	for _, s := range m.searches {
+		if s.done {
+			done++
+		}
+	}
+	return done
 }
 
-func (m installModel) search() (tea.Model, tea.Cmd) {
+// next moves on to the next query, installing the picked packages after the last one.
+func (m installModel) next() (tea.Model, tea.Cmd) {
 	if m.current_query_idx < len(m.queries)-1 {
 		m.isSearching = true
 		m.isInstalling = false
 		m.current_query_idx += 1
 		m.searchingTerm = m.queries[m.current_query_idx]
-		return m, searchCmd(m.searchingTerm)
+		_, m.queryVersion = util.SplitQuery(m.searchingTerm)
+		m.list = list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+		if m.searches[m.current_query_idx].done {
+			return m.handleSearch()
+		}
+		return m, nil
 	}
-	return m.end(nil)
+	return m.installPending()
+}
+
+// handleSearch shows the finished search of the current query, or picks its first result.
+func (m installModel) handleSearch() (tea.Model, tea.Cmd) {
+	search := m.searches[m.current_query_idx]
+	if search.err != nil {
+		m.isSearching = false
+		m.searchErr = search.err
+		return m, nil
+	}
+	if search.warning != "" {
+		m.warnings = append(m.warnings, fmt.Sprintf("Search for '%s' failed, %s", m.searchingTerm, search.warning))
+	}
+	m.results = search.results
+	if len(search.results) == 0 {
+		return m.skipNoMatch()
+	}
+	if m.picked >= 0 && m.picked < len(search.results) {
+		// the result was picked before the model started
+		m.isSearching = false
+		m.list = newSelectList([]list.Item{search.results[m.picked]}, "", true)
+		m.picked = -1
+		return m.install()
+	}
+	if m.selectFirst {
+		return m.install()
+	}
+	return m.showSearchResults()
+}
+
+// retrySearch runs the failed search again.
+func (m installModel) retrySearch() (tea.Model, tea.Cmd) {
+	m.searchErr = nil
+	m.isSearching = true
+	m.searches[m.current_query_idx] = querySearch{}
+	return m, tea.Batch(m.spinner.Tick, m.searchCmd(m.current_query_idx))
+}
+
+// skipSearch records the failed search and moves on to the next query.
+func (m installModel) skipSearch() (tea.Model, tea.Cmd) {
+	m = m.recordSearchError()
+	return m.next()
+}
+
+// recordSearchError adds the failed search to the history and clears the error.
+func (m installModel) recordSearchError() installModel {
+	s := fmt.Sprintf("Error searching '%s': %s", m.searchingTerm, m.searchErr.Error())
+	m.installationHistory = append(m.installationHistory, installResult{
+		title:   s,
+		success: false,
+		result: util.InstallResult{
+			Query:  m.queries[m.current_query_idx],
+			Status: util.StatusSearchFailed,
+			Error:  m.searchErr.Error(),
+		},
+	})
+	m.searchErr = nil
+	return m
+}
+
+// skipNoMatch records that the current query found nothing and moves on to the next query.
+func (m installModel) skipNoMatch() (tea.Model, tea.Cmd) {
+	s := fmt.Sprintf("No packages found for '%s'", m.searchingTerm)
+	m.installationHistory = append(m.installationHistory, installResult{
+		title:   s,
+		success: false,
+		result: util.InstallResult{
+			Query:  m.queries[m.current_query_idx],
+			Status: util.StatusNoMatch,
+			Error:  s,
+		},
+	})
+	return m.next()
 }
 
 func (m installModel) showSearchResults() (tea.Model, tea.Cmd) {
 	m.isSearching = false
 	m.isInstalling = false
 
+	// a missing favorites file only means nothing is starred
+	starred := map[string]bool{}
+	if favorites, err := config.LoadFavorites(); err == nil {
+		for _, f := range favorites {
+			starred[f.Path] = true
+		}
+	}
+	items := make([]list.DefaultItem, len(m.results))
+	for i, item := range m.results {
+		s := item.(searchResult)
+		s.starred = starred[s.result.ImportPath]
+		items[i] = s
+	}
+	m.resultsTitle = "Search Result: " + m.searchingTerm
+	m.list = newSelectList(newCheckItems(items), m.resultsTitle, true)
+	m.list.Title = selectionTitle(m.resultsTitle, m.list)
+	m.list.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{toggleKey, starKey, readmeKey}
+	}
+
+	m.searchingTerm = ""
+	m.installingTerm = ""
+
+	return m.loadDetails()
+}
+
+// isShowingResults reports whether the search results of the current query are shown.
+func (m installModel) isShowingResults() bool {
+	return !m.isSearching && !m.isListingVersions && !m.isInstalling && !m.isPickingVersion &&
+		!m.isDone && !m.isCancelling && m.searchErr == nil
+}
+
+// toggleStar stars the highlighted search result, or unstars it, saving the favorites right away.
+func (m installModel) toggleStar() (tea.Model, tea.Cmd) {
+	item := m.list.SelectedItem()
+	s, ok := unwrapResult(item)
+	if !ok {
+		return m, nil
+	}
+
+	var err error
+	if s.starred {
+		_, err = config.RemoveFavorite(s.result.ImportPath)
+	} else {
+		err = config.AddFavorite(config.Favorite{Path: s.result.ImportPath, Synopsis: s.result.Synopsis})
+	}
+	if err != nil {
+		m.warnings = append(m.warnings, "Could not save the favorites: "+err.Error())
+		return m, nil
+	}
+
+	s.starred = !s.starred
+	if c, ok := item.(checkItem); ok {
+		c.DefaultItem = s
+		item = c
+	} else {
+		item = s
+	}
+	return m, m.list.SetItem(m.list.Index(), item)
+}
+
+// unwrapResult returns the search result of a list item, which is wrapped in a checkItem
+// in the search results list.
+func unwrapResult(item list.Item) (searchResult, bool) {
+	if c, ok := item.(checkItem); ok {
+		item = c.DefaultItem
+	}
+	s, ok := item.(searchResult)
+	return s, ok
+}
+
+// loadDetails starts loading the details of the highlighted search result unless they
+// were loaded already.
+func (m installModel) loadDetails() (installModel, tea.Cmd) {
+	s, ok := unwrapResult(m.list.SelectedItem())
+	if !ok {
+		return m, nil
+	}
+	if _, ok := m.details[s.result.ImportPath]; ok {
+		return m, nil
+	}
+	m.details[s.result.ImportPath] = packageDetails{details: util.PackageDetails{SearchResult: s.result}, loading: true}
+	return m, detailsCmd(m.ctx, m.proxy, s.result, m.offline)
+}
+
+// renderSelectedDetails renders the details panel of the highlighted search result.
+func (m installModel) renderSelectedDetails() string {
+	s, ok := unwrapResult(m.list.SelectedItem())
+	if !ok {
+		return ""
+	}
+	d, ok := m.details[s.result.ImportPath]
+	if !ok {
+		d = packageDetails{details: util.PackageDetails{SearchResult: s.result}, loading: true}
+	}
+	return renderDetails(d, m.spinner.View())
+}
+
+// openReadme loads the README of the highlighted search result, at the latest version
+// when the details know it.
+func (m installModel) openReadme() (tea.Model, tea.Cmd) {
+	s, ok := unwrapResult(m.list.SelectedItem())
+	if !ok {
+		return m, nil
+	}
+	version := s.result.Version
+	if d, ok := m.details[s.result.ImportPath]; ok && d.details.LatestVersion != "" {
+		version = d.details.LatestVersion
+	}
+	m.readmeFor = s.result.ImportPath
+	m.isLoadingReadme = true
+	return m, readmeCmd(m.ctx, m.proxy, s.result, version, m.offline)
+}
+
+func (m installModel) showReadme(msg afterReadmeMsg) (tea.Model, tea.Cmd) {
+	// the user went back to the results before it loaded
+	if !m.isLoadingReadme || msg.importPath != m.readmeFor {
+		return m, nil
+	}
+	m.isLoadingReadme = false
+	m.isReadingReadme = true
+	if msg.err != nil {
+		m.readme.SetContent(errText.Render("Could not load the README: " + msg.err.Error()))
+	} else {
+		m.readme.SetContent(renderMarkdown(msg.readme, outputWidth-2))
+	}
+	m.readme.GotoTop()
+	return m, nil
+}
+
+// updateReadme scrolls the README, o, q and esc go back to the search results.
+func (m installModel) updateReadme(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.recordCancelled(m.current_query_idx).quit(msg.String())
+	case "o", "q", "esc":
+		m.isLoadingReadme = false
+		m.isReadingReadme = false
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.readme, cmd = m.readme.Update(msg)
+	return m, cmd
+}
+
+// newSelectList creates a list to pick a single item with enter, filtering is disabled.
+func newSelectList(items []list.Item, title string, showDescription bool) list.Model {
 	delegate := list.NewDefaultDelegate()
-	delegate.ShowDescription = false
+	delegate.ShowDescription = showDescription
 
 	keys := list.DefaultKeyMap()
 
@@ -207,28 +815,96 @@ func (m installModel) showSearchResults() (tea.Model, tea.Cmd) {
 	keys.AcceptWhileFiltering.SetEnabled(false)
 	keys.ClearFilter.SetEnabled(false)
 
-	m.list = list.New(m.results, delegate, 50, 20)
-	m.list.SetFilteringEnabled(false)
-	m.list.Title = "Search Result: " + m.searchingTerm
-	m.list.KeyMap = keys
-
-	m.searchingTerm = ""
-	m.installingTerm = ""
+	l := list.New(items, delegate, 50, 20)
+	l.SetFilteringEnabled(false)
+	l.Title = title
+	l.KeyMap = keys
 
-	return m, nil
+	return l
 }
 
-func (m installModel) recordHistory(err error) installModel {
+// recordHistory adds the outcome of installing p to the history. log is the output of the
+// go command, a failed install keeps its own output when it was retried alone.
+func (m installModel) recordHistory(p pendingInstall, outcome installOutcome, log string) installModel {
 	var s string
-	if err != nil {
-		s = fmt.Sprintf("Error installing '%s': %s", m.installingTerm, err.Error())
+	if outcome.Err != nil {
+		// the full go output is in the results, keep the history to one line
+		s = fmt.Sprintf("Error installing '%s': %s", p.pkg, strings.SplitN(outcome.Err.Error(), "\n", 2)[0])
+		var goErr util.GoCommandError
+		if errors.As(outcome.Err, &goErr) {
+			log = goErr.Stderr
+		}
+	} else if outcome.Location != "" {
+		s = fmt.Sprintf("Successfully installed '%s' to %s", p.pkg, outcome.Location)
 	} else {
-		s = fmt.Sprintf("Successfully installed '%s'", m.installingTerm)
+		s = fmt.Sprintf("Successfully installed '%s'", p.pkg)
+	}
+	result := util.InstallResult{Query: m.queries[p.query], Status: util.StatusOK}
+	result.Path, result.Version = util.SplitQuery(p.pkg)
+	if outcome.Version != "" {
+		result.Version = outcome.Version
 	}
-	m.installationHistory = append(m.installationHistory, installResult{title: s, success: err == nil})
+	if outcome.Err != nil {
+		result.Status = util.StatusInstallFailed
+		result.Error = outcome.Err.Error()
+	}
+	m.installationHistory = append(m.installationHistory, installResult{title: s, success: outcome.Err == nil, result: result, log: log})
 	return m
 }
 
+// updateReview moves through the history and expands the output of an entry. handled is
+// false for the keys the review does not use.
+func (m installModel) updateReview(msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, handled bool) {
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.installationHistory)-1 {
+			m.cursor++
+		}
+	case "enter":
+		if m.expanded == m.cursor {
+			m.expanded = -1
+			break
+		}
+		m.expanded = m.cursor
+		m.output.SetContent(m.installationHistory[m.cursor].log)
+		m.output.GotoTop()
+	case "pgup", "pgdown", "ctrl+u", "ctrl+d":
+		m.output, cmd = m.output.Update(msg)
+	default:
+		return m, nil, false
+	}
+	return m, cmd, true
+}
+
+// renderReview renders the history with the highlighted entry and the expanded output.
+func (m installModel) renderReview() string {
+	var builder strings.Builder
+	for i, record := range m.installationHistory {
+		marker := "  "
+		if i == m.cursor {
+			marker = "> "
+		}
+		style := okText
+		if !record.success {
+			style = errText
+		}
+		builder.WriteString(marker + style.Render(record.title) + "\n")
+		if i == m.expanded {
+			if record.log == "" {
+				builder.WriteString(outputStyle.Render("No output") + "\n")
+			} else {
+				builder.WriteString(outputStyle.Render(m.output.View()) + "\n")
+			}
+		}
+	}
+	builder.WriteString("\n" + helpText.Render("↑/↓: select • enter: show output • pgup/pgdown: scroll • q: quit") + "\n")
+	return builder.String()
+}
+
 func (m installModel) renderHistory() string {
 	var builder strings.Builder
 	for _, record := range m.installationHistory {
@@ -238,9 +914,55 @@ func (m installModel) renderHistory() string {
 			builder.WriteString(errText.Render(record.title) + "\n")
 		}
 	}
+	for _, warning := range m.warnings {
+		builder.WriteString(warnText.Render(warning) + "\n")
+	}
 	return builder.String()
 }
 
+// recordCancelled adds the picked packages and the queries from first on to the history as
+// cancelled, the user quit before they were installed. A running install records its own outcome.
+func (m installModel) recordCancelled(first int) installModel {
+	if m.isInstalling || m.isCancelling || m.isDone {
+		return m
+	}
+	for _, p := range m.pending {
+		result := util.InstallResult{Query: m.queries[p.query], Status: util.StatusCancelled, Error: "quit before installing"}
+		result.Path, result.Version = util.SplitQuery(p.pkg)
+		m.installationHistory = append(m.installationHistory, installResult{
+			title:  fmt.Sprintf("Cancelled '%s'", p.pkg),
+			result: result,
+		})
+	}
+	m.pending = nil
+	for i := first; i < len(m.queries); i++ {
+		m.installationHistory = append(m.installationHistory, installResult{
+			title: fmt.Sprintf("Skipped '%s'", m.queries[i]),
+			result: util.InstallResult{
+				Query:  m.queries[i],
+				Status: util.StatusCancelled,
+				Error:  "quit before picking a package",
+			},
+		})
+	}
+	return m
+}
+
+// quit cancels the searches and installs in flight. A running go command is waited
+// for so it is killed before the program exits.
+func (m installModel) quit(key string) (tea.Model, tea.Cmd) {
+	m.cancel()
+	if m.isInstalling {
+		m.isCancelling = true
+		m.quitKey = key
+		return m, nil
+	}
+	if m.asComponent {
+		return m, quitCmd(key, m.name)
+	}
+	return m, tea.Quit
+}
+
 func (m installModel) end(err error) (tea.Model, tea.Cmd) {
 	m.isSearching = false
 	m.isInstalling = false
@@ -248,6 +970,16 @@ func (m installModel) end(err error) (tea.Model, tea.Cmd) {
 	m.installingTerm = ""
 	m.isDone = true
 	m.err = err
+
+	// stay around so the user can read why the installs failed
+	for i, record := range m.installationHistory {
+		if !record.success && record.log != "" {
+			m.isReviewing = true
+			m.cursor = i
+			return m, nil
+		}
+	}
+
 	if m.asComponent {
 		return m, quitCmd("", m.name)
 	}
@@ -255,33 +987,135 @@ func (m installModel) end(err error) (tea.Model, tea.Cmd) {
 }
 
 type afterSearchMsg struct {
+	// query is the index of the query that was searched
+	query   int
 	results []list.Item
+	err     error
+	warning string
 }
 
-// searchCmd searches the go packages and returns a tea.Msg so that the searchCmd model
-// can update the TUI.
-func searchCmd(term string) tea.Cmd {
+// searchCmd searches the go packages for the query and returns a tea.Msg so that the model
+// can update the TUI. At most util.MaxConcurrentSearches searches run at the same time.
+func (m installModel) searchCmd(query int) tea.Cmd {
+	term, _ := util.SplitQuery(m.queries[query])
+	ctx, searcher, slots := m.ctx, m.searcher, m.searchSlots
 	return func() tea.Msg {
-		results := util.Search(term)
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return afterSearchMsg{query: query, err: ctx.Err()}
+		}
+
+		results, err := searcher.Search(ctx, term)
+		var stale util.StaleResultsError
+		if errors.As(err, &stale) {
+			// the backend is unreachable, the cached results are better than nothing
+			err = nil
+		}
+		if err != nil {
+			return afterSearchMsg{query: query, err: err}
+		}
 		msg := afterSearchMsg{
+			query:   query,
 			results: make([]list.Item, len(results)),
 		}
+		if stale.Err != nil {
+			msg.warning = stale.Error()
+		}
 		for i, res := range results {
-			msg.results[i] = searchResult(res)
+			msg.results[i] = searchResult{result: res}
 		}
 		return msg
 	}
 }
 
+type afterVersionsMsg struct {
+	versions []string
+	err      error
+}
+
+// versionsCmd lists the versions of the module so the user can pick one. Offline, only the
+// versions in the module cache are listed.
+func versionsCmd(ctx context.Context, modulePath string, offline bool) tea.Cmd {
+	return func() tea.Msg {
+		if offline {
+			versions, err := util.ListCachedVersions(ctx, "", modulePath)
+			return afterVersionsMsg{versions: versions, err: err}
+		}
+		versions, err := util.ListVersions(ctx, modulePath)
+		return afterVersionsMsg{versions: versions, err: err}
+	}
+}
+
 type afterInstallMsg struct {
+	// Pending are the installs the command ran
+	Pending []pendingInstall
+	// Outcomes are in the same order as Pending
+	Outcomes []installOutcome
+	// Log is everything the go command wrote
+	Log string
+}
+
+type installOutcome struct {
 	Err error
+	// Location is where the binary landed when installing with 'go install'
+	Location string
+	// Version is the version that ended up in go.mod when installing with 'go get'
+	Version string
 }
 
-func (m installModel) installCmd(term string) tea.Cmd {
+// installCmd installs the pending packages, streaming the output of the go command to ch.
+func (m installModel) installCmd(ch chan string) tea.Cmd {
+	if m.goInstall {
+		return goInstallCmd(m.ctx, m.runner(), m.pending, ch)
+	}
+	ctx, runner, pending := m.ctx, m.runner(), m.pending
 	return func() tea.Msg {
-		pkg := util.GetPkgUrl(term)
-		err := util.RunGoGet(pkg)
-		return afterInstallMsg{Err: err}
+		w := newLineWriter(ch)
+		defer w.Close()
+
+		pkgs := make([]string, len(pending))
+		for i, p := range pending {
+			pkgs[i] = p.pkg
+		}
+
+		runner.Output = w
+		errs := runner.GetAll(ctx, pkgs)
+
+		outcomes := make([]installOutcome, len(pending))
+		for i, p := range pending {
+			outcomes[i].Err = errs[i]
+			if errs[i] == nil {
+				// not knowing the resolved version is not a failure
+				outcomes[i].Version, _ = util.ModuleVersion(ctx, p.module)
+			}
+		}
+		return afterInstallMsg{Pending: pending, Outcomes: outcomes, Log: w.String()}
+	}
+}
+
+// goInstallCmd installs the pending binaries one by one, go install can't mix modules.
+func goInstallCmd(ctx context.Context, runner util.GoRunner, pending []pendingInstall, ch chan string) tea.Cmd {
+	return func() tea.Msg {
+		w := newLineWriter(ch)
+		defer w.Close()
+
+		runner.Output = w
+		dir, dirErr := util.BinaryDir(ctx)
+
+		outcomes := make([]installOutcome, len(pending))
+		for i, p := range pending {
+			if err := runner.Install(ctx, p.pkg); err != nil {
+				outcomes[i].Err = err
+				continue
+			}
+			// when dirErr is set the binary is installed, just don't know where
+			if dirErr == nil {
+				outcomes[i].Location = filepath.Join(dir, util.BinaryName(p.pkg))
+			}
+		}
+		return afterInstallMsg{Pending: pending, Outcomes: outcomes, Log: w.String()}
 	}
 }
 
@@ -1,19 +1,97 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/juancwu/gopack/util"
 )
 
+var (
+	upgradeKey = key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "upgrade"),
+	)
+	removeKey = key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "remove"),
+	)
+	docsKey = key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open docs"),
+	)
+	copyKey = key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "copy path"),
+	)
+	whyKey = key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "why"),
+	)
+	refreshKey = key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "refresh"),
+	)
+)
+
 type packageItem struct {
 	pkg util.Package
 }
 
+// listModel shows the packages of the module and runs actions on the highlighted one
 type listModel struct {
-	List list.Model
+	// ctx is cancelled when the user quits, killing the go command of the running action
+	ctx    context.Context
+	cancel context.CancelFunc
+	// isCancelling is true while waiting for the cancelled action to roll back before quitting
+	isCancelling bool
+	List         list.Model
+	versions     list.Model
+	why          viewport.Model
+	spinner      spinner.Model
+	offline      bool
+	// height is the room for the list and the status bar under it
+	height int
+	// busy describes the action running in the background, empty when idle
+	busy string
+	// status is the outcome of the last action, shown under the list
+	status    string
+	statusErr bool
+	// target is the package the versions, the remove prompt or the why output are for
+	target util.Package
+	// isPickingVersion is true while the versions list is shown
+	isPickingVersion bool
+	// isConfirmingRemove is true while waiting for y/n to remove the target
+	isConfirmingRemove bool
+	// isReadingWhy is true while the output of 'go mod why' is shown
+	isReadingWhy bool
+}
+
+// afterActionMsg is sent when an action on a package is done
+type afterActionMsg struct {
+	// done describes what happened for the status bar
+	done string
+	err  error
+	// changed is true when go.mod changed and the packages must be listed again
+	changed bool
+}
+
+type afterRefreshMsg struct {
+	packages []util.Package
+	err      error
+}
+
+type afterWhyMsg struct {
+	why string
+	err error
 }
 
 func (i packageItem) Title() string { return i.pkg.Path }
@@ -30,33 +108,112 @@ func (i packageItem) Description() string {
 }
 func (i packageItem) FilterValue() string { return i.pkg.Path }
 
-func NewListModel(packages []util.Package) listModel {
-	items := make([]list.Item, len(packages))
-	for i, pkg := range packages {
-		items[i] = packageItem{pkg: pkg}
-	}
+func NewListModel(ctx context.Context, packages []util.Package) listModel {
+	ctx, cancel := context.WithCancel(ctx)
 
-	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l := list.New(packageItems(packages), list.NewDefaultDelegate(), 0, 0)
 	l.Title = "Installed Packages"
+	// u upgrades the highlighted package instead of going to the previous page
+	l.KeyMap.PrevPage.SetKeys("left", "h", "pgup", "b")
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{upgradeKey, pickVersionKey, removeKey}
+	}
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{upgradeKey, pickVersionKey, removeKey, docsKey, copyKey, whyKey, refreshKey}
+	}
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	return listModel{
-		List: l,
+		ctx:     ctx,
+		cancel:  cancel,
+		List:    l,
+		why:     viewport.New(outputWidth, outputHeight),
+		spinner: s,
 	}
 }
 
+// SetOffline makes the actions use the module cache without reaching the network.
+func (m *listModel) SetOffline(enabled bool) {
+	m.offline = enabled
+}
+
 func (m listModel) Init() tea.Cmd {
 	return nil
 }
 
 func (m listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.isCancelling {
+		switch msg.(type) {
+		case afterActionMsg, afterRefreshMsg, afterVersionsMsg, afterWhyMsg:
+			// the action is over and has rolled back what it changed, now it is safe to quit
+			return m, tea.Quit
+		case tea.KeyMsg:
+			return m, nil
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			return m, tea.Quit
+			return m.quit()
+		}
+		if m.isReadingWhy {
+			return m.updateWhy(msg)
+		}
+		if m.isPickingVersion {
+			return m.updateVersions(msg)
+		}
+		if m.isConfirmingRemove {
+			return m.updateConfirmRemove(msg)
+		}
+		// let the filter input have all the keys while filtering
+		if m.List.FilterState() == list.Filtering {
+			break
+		}
+		// esc clears an applied filter before it quits
+		clearsFilter := m.List.FilterState() == list.FilterApplied && key.Matches(msg, m.List.KeyMap.ClearFilter)
+		if key.Matches(msg, m.List.KeyMap.Quit) && !clearsFilter {
+			return m.quit()
+		}
+		if key.Matches(msg, upgradeKey, pickVersionKey, removeKey, docsKey, copyKey, whyKey, refreshKey) {
+			return m.act(msg)
 		}
 	case tea.WindowSizeMsg:
 		h, v := docStyle.GetFrameSize()
-		m.List.SetSize(msg.Width-h, msg.Height-v)
+		m.height = msg.Height - v
+		m.List.SetWidth(msg.Width - h)
+		m.fitList()
+	case afterActionMsg:
+		m.busy = ""
+		m.setStatus(msg.done, msg.err)
+		if msg.changed {
+			return m.start("Refreshing the packages", refreshCmd(m.ctx))
+		}
+		return m, nil
+	case afterRefreshMsg:
+		return m.refresh(msg)
+	case afterVersionsMsg:
+		return m.showVersions(msg)
+	case afterWhyMsg:
+		m.busy = ""
+		if msg.err != nil {
+			m.setStatus("", msg.err)
+			return m, nil
+		}
+		m.why.SetContent(msg.why)
+		m.why.GotoTop()
+		m.isReadingWhy = true
+		return m, nil
+	case spinner.TickMsg:
+		if m.busy != "" {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -64,6 +221,311 @@ func (m listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// act runs the action bound to the key on the highlighted package, one action at a time.
+func (m listModel) act(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.busy != "" {
+		return m, nil
+	}
+	if key.Matches(msg, refreshKey) {
+		return m.start("Refreshing the packages", refreshCmd(m.ctx))
+	}
+
+	item, ok := m.List.SelectedItem().(packageItem)
+	if !ok {
+		return m, nil
+	}
+	pkg := item.pkg
+
+	switch {
+	case key.Matches(msg, docsKey):
+		return m.start("Opening the docs of "+pkg.Path, openDocsCmd(pkg))
+	case key.Matches(msg, copyKey):
+		return m.start("Copying "+pkg.Path, copyPathCmd(pkg.Path))
+	}
+
+	// the other actions change or inspect the requirements of the main module
+	if pkg.Main {
+		m.setStatus("", fmt.Errorf("%s is the main module", pkg.Path))
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, upgradeKey):
+		return m.start("Upgrading "+pkg.Path, changeVersionCmd(m.ctx, util.NewGoRunner(m.offline), pkg, "latest"))
+	case key.Matches(msg, pickVersionKey):
+		m.target = pkg
+		return m.start("Fetching the versions of "+pkg.Path, versionsCmd(m.ctx, pkg.Path, m.offline))
+	case key.Matches(msg, removeKey):
+		// go get module@none would also drop the modules that need an indirect dependency
+		if pkg.Indirect {
+			m.setStatus("", fmt.Errorf("%s is an indirect dependency, remove the modules that need it instead", pkg.Path))
+			return m, nil
+		}
+		m.target = pkg
+		m.isConfirmingRemove = true
+		return m, nil
+	case key.Matches(msg, whyKey):
+		m.target = pkg
+		return m.start("Looking up why "+pkg.Path+" is needed", whyCmd(m.ctx, pkg.Path))
+	}
+
+	return m, nil
+}
+
+// quit cancels the running action and waits for it to roll back go.mod and go.sum, so
+// its go command is killed and nothing is left half done when the program exits.
+func (m listModel) quit() (tea.Model, tea.Cmd) {
+	m.cancel()
+	if m.busy != "" {
+		m.isCancelling = true
+		m.busy = "Cancelling..."
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// start shows the action as busy in the status bar while cmd runs.
+func (m listModel) start(busy string, cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	m.busy = busy
+	return m, tea.Batch(m.spinner.Tick, cmd)
+}
+
+func (m *listModel) setStatus(done string, err error) {
+	m.statusErr = err != nil
+	if err != nil {
+		m.status = err.Error()
+	} else {
+		m.status = done
+	}
+	m.fitList()
+}
+
+// fitList shrinks the list to leave room for the status bar, go errors can take several lines.
+func (m *listModel) fitList() {
+	m.List.SetHeight(m.height - lipgloss.Height(m.status) - 1)
+}
+
+// refresh replaces the packages, keeping the highlighted one when it is still there.
+func (m listModel) refresh(msg afterRefreshMsg) (tea.Model, tea.Cmd) {
+	m.busy = ""
+	if msg.err != nil {
+		m.setStatus("", fmt.Errorf("could not list the packages: %v", msg.err))
+		return m, nil
+	}
+
+	var selected string
+	if item, ok := m.List.SelectedItem().(packageItem); ok {
+		selected = item.pkg.Path
+	}
+	cmd := m.List.SetItems(packageItems(msg.packages))
+	for i, pkg := range msg.packages {
+		if pkg.Path == selected {
+			m.List.Select(i)
+			return m, cmd
+		}
+	}
+	if n := len(msg.packages); n > 0 && m.List.Index() >= n {
+		m.List.Select(n - 1)
+	}
+	return m, cmd
+}
+
+func (m listModel) showVersions(msg afterVersionsMsg) (tea.Model, tea.Cmd) {
+	m.busy = ""
+	if len(msg.versions) == 0 {
+		if msg.err != nil {
+			m.setStatus("", fmt.Errorf("could not list the versions of %s: %v", m.target.Path, msg.err))
+		} else {
+			m.setStatus("", fmt.Errorf("no versions of %s found", m.target.Path))
+		}
+		return m, nil
+	}
+
+	items := make([]list.Item, len(msg.versions))
+	for i, v := range msg.versions {
+		items[i] = versionItem(v)
+	}
+	title := fmt.Sprintf("Versions of %s (at %s)", m.target.Path, m.target.Version)
+	m.versions = newSelectList(items, title, false)
+	m.isPickingVersion = true
+	return m, nil
+}
+
+// updateVersions changes the target to the version picked with enter, q and esc go back.
+func (m listModel) updateVersions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.isPickingVersion = false
+		return m, nil
+	case "enter":
+		v, ok := m.versions.SelectedItem().(versionItem)
+		if !ok {
+			return m, nil
+		}
+		m.isPickingVersion = false
+		busy := fmt.Sprintf("Changing %s to %s", m.target.Path, v)
+		return m.start(busy, changeVersionCmd(m.ctx, util.NewGoRunner(m.offline), m.target, string(v)))
+	}
+
+	var cmd tea.Cmd
+	m.versions, cmd = m.versions.Update(msg)
+	return m, cmd
+}
+
+func (m listModel) updateConfirmRemove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.isConfirmingRemove = false
+	if strings.ToLower(msg.String()) != "y" {
+		m.setStatus("Remove cancelled", nil)
+		return m, nil
+	}
+	return m.start("Removing "+m.target.Path, removeCmd(m.ctx, util.NewGoRunner(m.offline), m.target.Path))
+}
+
+// updateWhy scrolls the output of 'go mod why', w, q and esc go back to the list.
+func (m listModel) updateWhy(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "w", "q", "esc":
+		m.isReadingWhy = false
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.why, cmd = m.why.Update(msg)
+	return m, cmd
+}
+
 func (m listModel) View() string {
-	return docStyle.Render(m.List.View())
+	if m.isReadingWhy {
+		var builder strings.Builder
+		builder.WriteString(headingStyle.Render("Why is "+m.target.Path+" needed") + "\n\n")
+		builder.WriteString(outputStyle.Render(m.why.View()) + "\n\n")
+		builder.WriteString(helpText.Render("↑/↓: scroll • w/esc: back to the packages") + "\n")
+		return wrapper.Render(builder.String())
+	}
+	if m.isPickingVersion {
+		return wrapper.Render(m.versions.View())
+	}
+	return docStyle.Render(m.List.View() + "\n\n" + m.statusView())
+}
+
+// statusView is the status bar: the running action, the remove prompt or the outcome of the last action.
+func (m listModel) statusView() string {
+	switch {
+	case m.busy != "":
+		return m.spinner.View() + " " + m.busy
+	case m.isConfirmingRemove:
+		return warnText.Render(fmt.Sprintf("Remove %s? [y/N]", m.target.Path))
+	case m.statusErr:
+		return errText.Render(m.status)
+	case m.status != "":
+		return okText.Render(m.status)
+	}
+	return ""
+}
+
+func packageItems(packages []util.Package) []list.Item {
+	items := make([]list.Item, len(packages))
+	for i, pkg := range packages {
+		items[i] = packageItem{pkg: pkg}
+	}
+	return items
+}
+
+func refreshCmd(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		packages, err := util.GetDependencyList(ctx)
+		return afterRefreshMsg{packages: packages, err: err}
+	}
+}
+
+// changeVersionCmd moves the package to version with 'go get' and 'go mod tidy', rolling
+// go.mod and go.sum back if either fails.
+func changeVersionCmd(ctx context.Context, runner util.GoRunner, pkg util.Package, version string) tea.Cmd {
+	return func() tea.Msg {
+		restore, err := util.BackupModFiles()
+		if err != nil {
+			return afterActionMsg{err: err}
+		}
+		rollback := func(reason error) tea.Msg {
+			if err := restore(); err != nil {
+				reason = fmt.Errorf("%v, could not roll back go.mod and go.sum: %v", reason, err)
+			}
+			return afterActionMsg{err: reason}
+		}
+
+		if err := runner.Get(ctx, util.JoinVersion(pkg.Path, version)); err != nil {
+			return rollback(fmt.Errorf("error getting %s: %v", util.JoinVersion(pkg.Path, version), err))
+		}
+		if err := runner.Run(ctx, "mod", "tidy"); err != nil {
+			return rollback(fmt.Errorf("error running go mod tidy: %v", err))
+		}
+
+		current, err := util.ModuleVersion(ctx, pkg.Path)
+		if err != nil {
+			return afterActionMsg{err: err, changed: true}
+		}
+		if current == pkg.Version {
+			return afterActionMsg{done: fmt.Sprintf("%s is already at %s", pkg.Path, current)}
+		}
+		return afterActionMsg{done: fmt.Sprintf("Changed %s from %s to %s", pkg.Path, pkg.Version, current), changed: true}
+	}
+}
+
+// removeCmd drops the module like gop remove, with 'go get module@none' and 'go mod tidy',
+// and rolls go.mod and go.sum back when either fails. It refuses while the module is
+// imported, go mod tidy would add it back.
+func removeCmd(ctx context.Context, runner util.GoRunner, module string) tea.Cmd {
+	return func() tea.Msg {
+		importers, err := util.FindImporters(ctx, module)
+		if err != nil {
+			return afterActionMsg{err: fmt.Errorf("could not check the importers of %s: %v", module, err)}
+		}
+		if len(importers) > 0 {
+			return afterActionMsg{err: fmt.Errorf("%s is still imported by %s", module, strings.Join(importers, ", "))}
+		}
+
+		restore, err := util.BackupModFiles()
+		if err != nil {
+			return afterActionMsg{err: err}
+		}
+		rollback := func(reason error) tea.Msg {
+			if err := restore(); err != nil {
+				reason = fmt.Errorf("%v, could not roll back go.mod and go.sum: %v", reason, err)
+			}
+			return afterActionMsg{err: reason}
+		}
+
+		if err := runner.Run(ctx, "get", module+"@none"); err != nil {
+			return rollback(fmt.Errorf("error removing %s: %v", module, err))
+		}
+		if err := runner.Run(ctx, "mod", "tidy"); err != nil {
+			return rollback(fmt.Errorf("error running go mod tidy: %v", err))
+		}
+		return afterActionMsg{done: "Removed " + module, changed: true}
+	}
+}
+
+func openDocsCmd(pkg util.Package) tea.Cmd {
+	return func() tea.Msg {
+		url := util.DocsURL(pkg.Path, pkg.Version)
+		if err := util.OpenURL(url); err != nil {
+			return afterActionMsg{err: err}
+		}
+		return afterActionMsg{done: "Opened " + url}
+	}
+}
+
+func copyPathCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(path); err != nil {
+			return afterActionMsg{err: fmt.Errorf("could not copy %s: %v", path, err)}
+		}
+		return afterActionMsg{done: "Copied " + path}
+	}
+}
+
+func whyCmd(ctx context.Context, module string) tea.Cmd {
+	return func() tea.Msg {
+		why, err := util.ModWhy(ctx, module)
+		return afterWhyMsg{why: why, err: err}
+	}
 }
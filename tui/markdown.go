@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	headingStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	codeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	quoteStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Italic(true)
+	linkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Underline(true)
+
+	// images can't be shown in a terminal, badges are dropped with them
+	mdImage      = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	mdEmptyLink  = regexp.MustCompile(`\[\s*\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdHTMLTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdListItem   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+)
+
+// renderMarkdown renders the markdown for the terminal, wrapped to width. Only the common
+// blocks are styled: headings, code, quotes, lists and links, the rest is kept as text.
+func renderMarkdown(md string, width int) string {
+	var builder strings.Builder
+	inCode := false
+	blank := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			builder.WriteString(codeStyle.Render("  "+line) + "\n")
+			continue
+		}
+
+		text := renderInline(trimmed)
+		if text == "" {
+			// drop the lines that only held images or html, and repeated blank lines
+			if trimmed == "" && !blank {
+				builder.WriteString("\n")
+				blank = true
+			}
+			continue
+		}
+		blank = false
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			text = strings.TrimSpace(strings.TrimLeft(text, "#"))
+			builder.WriteString(headingStyle.Width(width).Render(text) + "\n")
+		case strings.HasPrefix(trimmed, ">"):
+			text = strings.TrimSpace(strings.TrimLeft(text, ">"))
+			builder.WriteString(quoteStyle.Width(width).Render("│ "+text) + "\n")
+		case trimmed == "---" || trimmed == "***" || trimmed == "___":
+			builder.WriteString(helpText.Render(strings.Repeat("─", width)) + "\n")
+		case mdListItem.MatchString(line):
+			indent := mdListItem.FindStringSubmatch(line)[1]
+			text = indent + "• " + mdListItem.ReplaceAllString(text, "")
+			builder.WriteString(lipgloss.NewStyle().Width(width).Render(text) + "\n")
+		default:
+			builder.WriteString(lipgloss.NewStyle().Width(width).Render(text) + "\n")
+		}
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+// renderInline styles the links and code in a line and strips the emphasis markers.
+func renderInline(line string) string {
+	line = mdImage.ReplaceAllString(line, "")
+	line = mdEmptyLink.ReplaceAllString(line, "")
+	line = mdHTMLTag.ReplaceAllString(line, "")
+	line = mdLink.ReplaceAllStringFunc(line, func(s string) string {
+		m := mdLink.FindStringSubmatch(s)
+		return linkStyle.Render(m[1])
+	})
+	line = mdBold.ReplaceAllString(line, "$1$2")
+	line = mdInlineCode.ReplaceAllStringFunc(line, func(s string) string {
+		return codeStyle.Render(strings.Trim(s, "`"))
+	})
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// checkItem wraps a list item so it can be toggled in a multi-select list
+type checkItem struct {
+	list.DefaultItem
+	checked bool
+}
+
+func (i checkItem) Title() string {
+	if i.checked {
+		return "[x] " + i.DefaultItem.Title()
+	}
+	return "[ ] " + i.DefaultItem.Title()
+}
+
+var toggleKey = key.NewBinding(
+	key.WithKeys(" "),
+	key.WithHelp("space", "toggle"),
+)
+
+// newCheckItems wraps every item in a checkItem, all unchecked
+func newCheckItems(items []list.DefaultItem) []list.Item {
+	checkItems := make([]list.Item, len(items))
+	for i, item := range items {
+		checkItems[i] = checkItem{DefaultItem: item}
+	}
+	return checkItems
+}
+
+// toggleSelected flips the checked state of the highlighted item
+func toggleSelected(l *list.Model) {
+	item, ok := l.SelectedItem().(checkItem)
+	if !ok {
+		return
+	}
+	item.checked = !item.checked
+	l.SetItem(l.Index(), item)
+}
+
+// checkedItems returns the wrapped items that are checked
+func checkedItems(l list.Model) []list.DefaultItem {
+	var items []list.DefaultItem
+	for _, item := range l.Items() {
+		if c, ok := item.(checkItem); ok && c.checked {
+			items = append(items, c.DefaultItem)
+		}
+	}
+	return items
+}
+
+// selectionTitle appends the number of checked items to the title
+func selectionTitle(title string, l list.Model) string {
+	return fmt.Sprintf("%s (%d selected)", title, len(checkedItems(l)))
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/juancwu/gopack/util"
+)
+
+// outdatedModel shows the current and available versions of the outdated dependencies
+type outdatedModel struct {
+	table    table.Model
+	packages []util.Package
+}
+
+func NewOutdatedModel(packages []util.Package) outdatedModel {
+	columns := []table.Column{
+		{Title: "Module", Width: 50},
+		{Title: "Current", Width: 20},
+		{Title: "Available", Width: 20},
+		{Title: "Bump", Width: 10},
+		{Title: "Type", Width: 10},
+		{Title: "Deprecated", Width: 10},
+	}
+
+	rows := make([]table.Row, len(packages))
+	for i, pkg := range packages {
+		// deprecated modules can be at their latest version
+		available, bump := "-", "-"
+		if pkg.Update != nil {
+			available, bump = pkg.Update.Version, util.Bump(pkg.Version, pkg.Update.Version)
+		}
+		deprecated := ""
+		if pkg.Deprecated != "" {
+			deprecated = "yes"
+		}
+		rows[i] = table.Row{
+			pkg.Path,
+			pkg.Version,
+			available,
+			bump,
+			pkg.DependencyType(),
+			deprecated,
+		}
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+	)
+
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		Bold(true)
+	styles.Selected = styles.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57"))
+	t.SetStyles(styles)
+
+	return outdatedModel{table: t, packages: packages}
+}
+
+func (m outdatedModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m outdatedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		_, v := docStyle.GetFrameSize()
+		// leave room for the header, the deprecation message and help line
+		m.table.SetHeight(msg.Height - v - 6)
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m outdatedModel) View() string {
+	return docStyle.Render(m.table.View() + "\n" + m.deprecationView() + "\n" + helpText.Render("↑/↓: navigate • q: quit"))
+}
+
+// deprecationView shows the deprecation message of the highlighted module, if any.
+func (m outdatedModel) deprecationView() string {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.packages) || m.packages[cursor].Deprecated == "" {
+		return "\n"
+	}
+	pkg := m.packages[cursor]
+	return "\n" + warnText.Render(pkg.Path+" is deprecated: "+pkg.Deprecated)
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lineWriter sends every complete line written to it over a channel so the TUI can
+// stream the output of a command, and keeps the whole output for later.
+type lineWriter struct {
+	mu      sync.Mutex
+	ch      chan string
+	partial string
+	all     strings.Builder
+}
+
+func newLineWriter(ch chan string) *lineWriter {
+	return &lineWriter{ch: ch}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.all.Write(p)
+	lines := strings.Split(w.partial+string(p), "\n")
+	// the last element is an unfinished line, or empty if p ended with a newline
+	w.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		w.ch <- line
+	}
+	return len(p), nil
+}
+
+// Close sends the unfinished line, if any, and closes the channel.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.partial != "" {
+		w.ch <- w.partial
+		w.partial = ""
+	}
+	close(w.ch)
+	return nil
+}
+
+// String returns everything written so far.
+func (w *lineWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.all.String()
+}
+
+// outputLineMsg carries a line of command output, ch is the channel it came from
+type outputLineMsg struct {
+	line string
+	ch   chan string
+}
+
+// waitForOutput waits for the next line on the channel, returning no msg when it is closed.
+func waitForOutput(ch chan string) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return outputLineMsg{line: line, ch: ch}
+	}
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/juancwu/gopack/util"
+)
+
+const removeTitle = "Remove Packages"
+
+var confirmKey = key.NewBinding(
+	key.WithKeys("enter"),
+	key.WithHelp("enter", "confirm"),
+)
+
+// removeModel lets the user pick the dependencies to remove
+type removeModel struct {
+	List      list.Model
+	confirmed bool
+}
+
+func NewRemoveModel(packages []util.Package) removeModel {
+	items := make([]list.DefaultItem, len(packages))
+	for i, pkg := range packages {
+		items[i] = packageItem{pkg: pkg}
+	}
+
+	l := list.New(newCheckItems(items), list.NewDefaultDelegate(), 0, 0)
+	l.Title = selectionTitle(removeTitle, l)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{toggleKey, confirmKey}
+	}
+
+	return removeModel{
+		List: l,
+	}
+}
+
+func (m removeModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m removeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// let the filter input have all the keys while filtering
+		if m.List.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case msg.String() == "ctrl+c":
+			return m, tea.Quit
+		case key.Matches(msg, toggleKey):
+			toggleSelected(&m.List)
+			m.List.Title = selectionTitle(removeTitle, m.List)
+			return m, nil
+		case key.Matches(msg, confirmKey):
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.List.SetSize(msg.Width-h, msg.Height-v)
+	}
+
+	var cmd tea.Cmd
+	m.List, cmd = m.List.Update(msg)
+	return m, cmd
+}
+
+func (m removeModel) View() string {
+	return docStyle.Render(m.List.View())
+}
+
+// SelectedModules returns the module paths checked in a confirmed remove model.
+func SelectedModules(model tea.Model) []string {
+	m, ok := model.(removeModel)
+	if !ok || !m.confirmed {
+		return nil
+	}
+	var modules []string
+	for _, item := range checkedItems(m.List) {
+		if p, ok := item.(packageItem); ok {
+			modules = append(modules, p.pkg.Path)
+		}
+	}
+	return modules
+}
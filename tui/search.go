@@ -1,12 +1,26 @@
 package tui
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/juancwu/gopack/util"
+)
+
+const (
+	// searchDebounce is how long the input must be left alone before it is searched
+	searchDebounce = 300 * time.Millisecond
+	// minLiveSearchLength is the shortest term searched while typing
+	minLiveSearchLength = 2
+	liveResultsWidth    = 80
+	liveResultsHeight   = 14
 )
 
 type searchModelState string
@@ -18,17 +32,21 @@ const (
 
 // searchModelKeyMap implements the help.KeyMap interface
 type searchModelKeyMap struct {
-	Enter key.Binding
-	Quit  key.Binding
+	Enter  key.Binding
+	Recall key.Binding
+	Browse key.Binding
+	Quit   key.Binding
 }
 
 func (k searchModelKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Enter, k.Quit}
+	return []key.Binding{k.Enter, k.Recall, k.Browse, k.Quit}
 }
 
 func (k searchModelKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Enter},
+		{k.Recall},
+		{k.Browse},
 		{k.Quit},
 	}
 }
@@ -39,10 +57,34 @@ type searchModel struct {
 	keys    searchModelKeyMap
 	help    help.Model
 	history string
+	// ctx and searcher are handed to every installModel created from the input
+	ctx      context.Context
+	searcher util.Searcher
+	// offline is handed to every installModel, see installModel.SetOffline
+	offline bool
 	im      tea.Model // installModel, not defined as installModel type because Go doesn't accept it
+	// results lists the matches of the live search below the input. They are kept until the
+	// search of the refined input finishes, resultsTerm is the term they were found for.
+	results     list.Model
+	resultsTerm string
+	// isBrowsing is true when the keys move through the results instead of editing the input
+	isBrowsing bool
+	// searchID identifies the latest live search, the older ones are ignored
+	searchID        int
+	isLiveSearching bool
+	cancelSearch    context.CancelFunc
+	liveErr         error
+	// recent are the past queries recalled with up, most recent first. recallIdx is the
+	// recalled query, -1 while typing a new one.
+	recent    []string
+	recallIdx int
+	// installed are the results of every finished installModel, see Results
+	installed []util.InstallResult
+	// favorites are the queries of the starred packages, installed by entering @fav
+	favorites []string
 }
 
-func NewSearchModel() searchModel {
+func NewSearchModel(ctx context.Context, searcher util.Searcher) searchModel {
 	ti := textinput.New()
 	ti.Placeholder = "Search Packages"
 	ti.Focus()
@@ -52,20 +94,53 @@ func NewSearchModel() searchModel {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "Search"),
 		),
+		Recall: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑", "Previous queries"),
+		),
+		Browse: key.NewBinding(
+			key.WithKeys("tab", "down"),
+			key.WithHelp("tab/↓", "Browse results"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("ctrl+c", "esc"),
 			key.WithHelp("esc", "Quit program"),
 		),
 	}
 
+	results := newSelectList(nil, "", true)
+	results.SetSize(liveResultsWidth, liveResultsHeight)
+	results.SetShowHelp(false)
+	// q is typed into the input, esc goes back to it
+	results.KeyMap.Quit.SetEnabled(false)
+
 	return searchModel{
-		ti:    ti,
-		state: inputState, // initial state in input mode
-		keys:  keyMap,
-		help:  help.New(),
+		ti:        ti,
+		state:     inputState, // initial state in input mode
+		keys:      keyMap,
+		help:      help.New(),
+		ctx:       ctx,
+		searcher:  searcher,
+		results:   results,
+		recallIdx: -1,
 	}
 }
 
+// SetFavorites sets the queries of the starred packages installed by entering @fav.
+func (m *searchModel) SetFavorites(queries []string) {
+	m.favorites = queries
+}
+
+// SetRecentQueries sets the past queries recalled with up in the input, most recent first.
+func (m *searchModel) SetRecentQueries(queries []string) {
+	m.recent = queries
+}
+
+// SetOffline makes the model install from the module cache without reaching the network.
+func (m *searchModel) SetOffline(enabled bool) {
+	m.offline = enabled
+}
+
 func (m searchModel) Init() tea.Cmd {
 	return nil
 }
@@ -85,6 +160,7 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.im, _ = m.im.Update(msg)
 			// add the latest recorded history
 			m.history += m.im.(installModel).History()
+			m.installed = append(m.installed, Results(m.im)...)
 			// reset the state for the next input
 			m.state = inputState
 		default:
@@ -95,36 +171,257 @@ func (m searchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	switch msg := msg.(type) {
+	case debounceMsg:
+		return m.liveSearch(msg)
+	case liveSearchMsg:
+		return m.showLiveResults(msg), nil
 	case tea.KeyMsg:
+		if m.isBrowsing {
+			return m.updateBrowsing(msg)
+		}
 		switch {
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
+		case key.Matches(msg, m.keys.Recall) && m.recallIdx < len(m.recent)-1:
+			return m.recall(m.recallIdx + 1)
+		case msg.String() == "down" && m.recallIdx >= 0:
+			return m.recall(m.recallIdx - 1)
+		case key.Matches(msg, m.keys.Browse):
+			if len(m.results.Items()) > 0 {
+				m.isBrowsing = true
+				m.ti.Blur()
+			}
+			return m, nil
 		case key.Matches(msg, m.keys.Enter):
-			model := NewInstallModel([]string{m.ti.Value()}, false)
-			model.SetAsComponent(true)
-			m.im = model
-			m.state = searchingState
-			m.ti.Reset()
-			cmd = m.im.Init()
-			return m, cmd
+			term := strings.TrimSpace(m.ti.Value())
+			m = m.remember(term)
+			if term == util.FavoritesQuery && len(m.favorites) > 0 {
+				// the starred packages are not searched, nothing to pick
+				return m.startInstall(m.newInstallModel(m.favorites, true))
+			}
+			if searched, _ := util.SplitQuery(term); searched != "" && searched == m.resultsTerm {
+				// searched already while typing
+				model := m.newInstallModel([]string{term}, false)
+				model.SetResults(m.liveResults(), -1)
+				return m.startInstall(model)
+			}
+			return m.startInstall(m.newInstallModel([]string{m.ti.Value()}, false))
 		}
 	}
 
+	value := m.ti.Value()
 	m.ti, cmd = m.ti.Update(msg)
+	if m.ti.Value() != value {
+		m.recallIdx = -1
+		debounceCmd := m.debounce()
+		return m, tea.Batch(cmd, debounceCmd)
+	}
 	return m, cmd
 }
 
+// recall puts the recent query at index i in the input, an empty input for -1.
+func (m searchModel) recall(i int) (tea.Model, tea.Cmd) {
+	m.recallIdx = i
+	if i < 0 {
+		m.ti.SetValue("")
+	} else {
+		m.ti.SetValue(m.recent[i])
+	}
+	m.ti.CursorEnd()
+	return m, m.debounce()
+}
+
+// remember makes term the most recent query.
+func (m searchModel) remember(term string) searchModel {
+	m.recallIdx = -1
+	if term == "" {
+		return m
+	}
+	recent := []string{term}
+	for _, query := range m.recent {
+		if query != term {
+			recent = append(recent, query)
+		}
+	}
+	m.recent = recent
+	return m
+}
+
+// updateBrowsing moves through the live results, enter installs the highlighted one.
+// Going up from the first result, tab or esc return to the input.
+func (m searchModel) updateBrowsing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "tab", "esc":
+		return m.stopBrowsing()
+	case "up", "k":
+		if m.results.Index() == 0 {
+			return m.stopBrowsing()
+		}
+	case "enter":
+		model := m.newInstallModel([]string{m.liveQuery()}, false)
+		model.SetResults(m.liveResults(), m.results.Index())
+		return m.startInstall(model)
+	}
+
+	var cmd tea.Cmd
+	m.results, cmd = m.results.Update(msg)
+	return m, cmd
+}
+
+func (m searchModel) stopBrowsing() (tea.Model, tea.Cmd) {
+	m.isBrowsing = false
+	return m, m.ti.Focus()
+}
+
+// liveResults returns the search results shown below the input.
+func (m searchModel) liveResults() []util.SearchResult {
+	results := make([]util.SearchResult, 0, len(m.results.Items()))
+	for _, item := range m.results.Items() {
+		if s, ok := item.(searchResult); ok {
+			results = append(results, s.result)
+		}
+	}
+	return results
+}
+
+// liveQuery returns the query of the live results, with the version typed in the input.
+func (m searchModel) liveQuery() string {
+	query := strings.TrimSpace(m.ti.Value())
+	if term, _ := util.SplitQuery(query); term == m.resultsTerm {
+		return query
+	}
+	return m.resultsTerm
+}
+
+// newInstallModel creates the installModel for the queries, running as a component of the search model.
+func (m searchModel) newInstallModel(queries []string, selectFirst bool) installModel {
+	model := NewInstallModel(m.ctx, queries, selectFirst, m.searcher)
+	model.SetAsComponent(true)
+	model.SetOffline(m.offline)
+	return model
+}
+
+// startInstall hands over to the installModel.
+func (m searchModel) startInstall(model installModel) (tea.Model, tea.Cmd) {
+
+	// the live search is of no use anymore
+	if m.cancelSearch != nil {
+		m.cancelSearch()
+	}
+	m.searchID++
+	m.isLiveSearching = false
+	m.isBrowsing = false
+
+	m.im = model
+	m.state = searchingState
+	m.ti.Reset()
+	m.ti.Focus()
+	return m, m.im.Init()
+}
+
+type debounceMsg struct {
+	id   int
+	term string
+}
+
+// debounce waits for the input to settle before searching it, every key press starts a
+// new wait and the earlier ones are ignored.
+func (m *searchModel) debounce() tea.Cmd {
+	m.searchID++
+	id := m.searchID
+	// the version of a query isn't part of the search
+	term, _ := util.SplitQuery(strings.TrimSpace(m.ti.Value()))
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return debounceMsg{id: id, term: term}
+	})
+}
+
+type liveSearchMsg struct {
+	id      int
+	term    string
+	results []util.SearchResult
+	err     error
+}
+
+// liveSearch searches the settled input, cancelling the search of the previous input.
+func (m searchModel) liveSearch(msg debounceMsg) (tea.Model, tea.Cmd) {
+	if msg.id != m.searchID {
+		return m, nil
+	}
+	if m.cancelSearch != nil {
+		m.cancelSearch()
+	}
+	if len(msg.term) < minLiveSearchLength {
+		m.isLiveSearching = false
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancelSearch = cancel
+	m.isLiveSearching = true
+	// the terms typed on the way to the query aren't worth keeping in the disk cache
+	searcher := util.Uncached(m.searcher)
+	return m, func() tea.Msg {
+		results, err := searcher.Search(ctx, msg.term)
+		return liveSearchMsg{id: msg.id, term: msg.term, results: results, err: err}
+	}
+}
+
+// showLiveResults replaces the results with the ones of the latest search, a failed search
+// keeps the previous results.
+func (m searchModel) showLiveResults(msg liveSearchMsg) searchModel {
+	if msg.id != m.searchID {
+		return m
+	}
+	m.isLiveSearching = false
+	m.liveErr = msg.err
+	if msg.err != nil {
+		return m
+	}
+
+	items := make([]list.Item, len(msg.results))
+	for i, res := range msg.results {
+		items[i] = searchResult{result: res}
+	}
+	m.results.SetItems(items)
+	m.results.Select(0)
+	m.results.Title = fmt.Sprintf("Results for '%s'", msg.term)
+	m.resultsTerm = msg.term
+	return m
+}
+
 func (m searchModel) View() string {
 	// let the installModel handle the rendering
 	if m.state == searchingState {
 		return m.im.View()
 	}
 
-	// render the input and history
+	// render the input, live results and history
 	views := []string{
 		m.ti.View(),
 		m.help.View(m.keys),
 	}
+
+	var status string
+	switch {
+	case m.isLiveSearching:
+		status = helpText.Render("Searching...")
+	case m.liveErr != nil:
+		status = errText.Render("Search failed: " + m.liveErr.Error())
+	}
+	if status != "" {
+		views = append(views, status)
+	}
+	if m.resultsTerm != "" {
+		if len(m.results.Items()) == 0 {
+			views = append(views, helpText.Render(fmt.Sprintf("No packages found for '%s'", m.resultsTerm)))
+		} else {
+			views = append(views, m.results.View())
+		}
+	}
+
 	if m.history != "" {
 		views = append(views, m.history)
 	}
@@ -7,5 +7,19 @@ var (
 
 	okText   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
 	errText  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	warnText = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffaa00"))
+	helpText = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	docStyle = lipgloss.NewStyle().Margin(1, 2)
+
+	// outputStyle frames the output of go commands
+	outputStyle = lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder(), false, false, false, true).
+			BorderForeground(lipgloss.Color("241")).
+			PaddingLeft(1).
+			Foreground(lipgloss.Color("245"))
+)
+
+const (
+	outputWidth  = 100
+	outputHeight = 8
 )
@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/juancwu/gopack/util"
+)
+
+const upgradeTitle = "Upgrade Packages"
+
+var pickVersionKey = key.NewBinding(
+	key.WithKeys("v"),
+	key.WithHelp("v", "pick version"),
+)
+
+// upgradeItem is an outdated package and the version it will be upgraded to
+type upgradeItem struct {
+	pkg    util.Package
+	target string
+}
+
+func (i upgradeItem) Title() string { return i.pkg.Path }
+func (i upgradeItem) Description() string {
+	return fmt.Sprintf("%s -> %s (%s, %s)", i.pkg.Version, i.target, util.Bump(i.pkg.Version, i.target), i.pkg.DependencyType())
+}
+func (i upgradeItem) FilterValue() string { return i.pkg.Path }
+
+// upgradeModel lets the user pick the packages to upgrade and the version of each one
+type upgradeModel struct {
+	// ctx cancels fetching the versions when the program is stopped
+	ctx      context.Context
+	List     list.Model
+	versions list.Model
+	spinner  spinner.Model
+	// isListingVersions is true while the versions of the highlighted package are fetched
+	isListingVersions bool
+	// isPickingVersion is true while the versions list is shown
+	isPickingVersion bool
+	confirmed        bool
+}
+
+func NewUpgradeModel(ctx context.Context, packages []util.Package) upgradeModel {
+	items := make([]list.DefaultItem, len(packages))
+	for i, pkg := range packages {
+		items[i] = upgradeItem{pkg: pkg, target: pkg.Update.Version}
+	}
+
+	l := list.New(newCheckItems(items), list.NewDefaultDelegate(), 0, 0)
+	l.Title = selectionTitle(upgradeTitle, l)
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{toggleKey, pickVersionKey, confirmKey}
+	}
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	return upgradeModel{
+		ctx:     ctx,
+		List:    l,
+		spinner: s,
+	}
+}
+
+func (m upgradeModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m upgradeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if m.isListingVersions {
+			return m, nil
+		}
+		if m.isPickingVersion {
+			return m.updateVersions(msg)
+		}
+		// let the filter input have all the keys while filtering
+		if m.List.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, toggleKey):
+			toggleSelected(&m.List)
+			m.List.Title = selectionTitle(upgradeTitle, m.List)
+			return m, nil
+		case key.Matches(msg, pickVersionKey):
+			item, ok := m.List.SelectedItem().(checkItem)
+			if !ok {
+				return m, nil
+			}
+			m.isListingVersions = true
+			return m, tea.Batch(m.spinner.Tick, versionsCmd(m.ctx, item.DefaultItem.(upgradeItem).pkg.Path, false))
+		case key.Matches(msg, confirmKey):
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		m.List.SetSize(msg.Width-h, msg.Height-v)
+	case afterVersionsMsg:
+		return m.showVersions(msg)
+	case spinner.TickMsg:
+		if m.isListingVersions {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.List, cmd = m.List.Update(msg)
+	return m, cmd
+}
+
+func (m upgradeModel) showVersions(msg afterVersionsMsg) (tea.Model, tea.Cmd) {
+	m.isListingVersions = false
+
+	item := m.List.SelectedItem().(checkItem)
+	pkg := item.DefaultItem.(upgradeItem).pkg
+
+	var items []list.Item
+	for _, v := range util.NewerVersions(msg.versions, pkg.Version) {
+		items = append(items, versionItem(v))
+	}
+	if len(items) == 0 {
+		// fall back to the update reported by go list
+		items = append(items, versionItem(pkg.Update.Version))
+	}
+
+	title := "Versions of " + pkg.Path
+	if msg.err != nil {
+		title += " (could not list versions: " + msg.err.Error() + ")"
+	}
+	m.versions = newSelectList(items, title, false)
+	m.isPickingVersion = true
+
+	return m, nil
+}
+
+func (m upgradeModel) updateVersions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.isPickingVersion = false
+		return m, nil
+	case "enter":
+		v, ok := m.versions.SelectedItem().(versionItem)
+		if !ok {
+			return m, nil
+		}
+		// picking a version also selects the package
+		item := m.List.SelectedItem().(checkItem)
+		upgrade := item.DefaultItem.(upgradeItem)
+		upgrade.target = string(v)
+		item.DefaultItem = upgrade
+		item.checked = true
+		m.List.SetItem(m.List.Index(), item)
+		m.List.Title = selectionTitle(upgradeTitle, m.List)
+		m.isPickingVersion = false
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.versions, cmd = m.versions.Update(msg)
+	return m, cmd
+}
+
+func (m upgradeModel) View() string {
+	if m.isListingVersions {
+		return wrapper.Render(m.spinner.View() + " Fetching versions")
+	}
+	if m.isPickingVersion {
+		return wrapper.Render(m.versions.View())
+	}
+	return docStyle.Render(m.List.View())
+}
+
+// SelectedUpgrades returns the checked packages as module@version of a confirmed upgrade model.
+func SelectedUpgrades(model tea.Model) []string {
+	m, ok := model.(upgradeModel)
+	if !ok || !m.confirmed {
+		return nil
+	}
+	var upgrades []string
+	for _, item := range checkedItems(m.List) {
+		if u, ok := item.(upgradeItem); ok {
+			upgrades = append(upgrades, util.JoinVersion(u.pkg.Path, u.target))
+		}
+	}
+	return upgrades
+}
@@ -0,0 +1,194 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached search results are served without searching again.
+const DefaultCacheTTL = 24 * time.Hour
+
+// searchCacheDir is the directory inside the cache directory that holds the search results
+const searchCacheDir = "search"
+
+// CachingSearcher serves search results from files in Dir while they are younger than TTL.
+// When the backend can't be reached, expired results are served with a StaleResultsError.
+type CachingSearcher struct {
+	Searcher Searcher
+	// Backend identifies the search backend, results of different backends are cached apart
+	Backend string
+	Dir     string
+	TTL     time.Duration
+	// Refresh skips fresh results and always searches, the new results are still cached
+	Refresh bool
+}
+
+func NewCachingSearcher(searcher Searcher, backend string, dir string, ttl time.Duration) *CachingSearcher {
+	return &CachingSearcher{
+		Searcher: searcher,
+		Backend:  backend,
+		Dir:      dir,
+		TTL:      ttl,
+	}
+}
+
+// cacheEntry is the content of a cache file
+type cacheEntry struct {
+	Backend string         `json:"backend"`
+	Term    string         `json:"term"`
+	Time    time.Time      `json:"time"`
+	Results []SearchResult `json:"results"`
+}
+
+// StaleResultsError is returned along with expired cached results when the search backend
+// could not be reached. The results are usable, the error explains how old they are.
+type StaleResultsError struct {
+	Cached time.Time
+	Err    error
+}
+
+func (e StaleResultsError) Error() string {
+	age := time.Since(e.Cached).Round(time.Minute)
+	return fmt.Sprintf("showing results cached %s ago: %v", age, e.Err)
+}
+
+func (e StaleResultsError) Unwrap() error {
+	return e.Err
+}
+
+func (s *CachingSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	path := s.path(term)
+	entry, cacheErr := readCacheEntry(path)
+	if cacheErr == nil && !s.Refresh && time.Since(entry.Time) < s.TTL {
+		return entry.Results, nil
+	}
+
+	results, err := s.Searcher.Search(ctx, term)
+	if err != nil {
+		var networkErr NetworkError
+		if cacheErr == nil && errors.As(err, &networkErr) && ctx.Err() == nil {
+			return entry.Results, StaleResultsError{Cached: entry.Time, Err: err}
+		}
+		return nil, err
+	}
+
+	// failing to cache doesn't fail the search
+	_ = writeCacheEntry(path, cacheEntry{Backend: s.Backend, Term: term, Time: time.Now(), Results: results})
+	return results, nil
+}
+
+// Uncached returns the searcher without its CachingSearcher, the searchers wrapped around
+// the cache, like a KnownSearcher, are kept. Searchers without a cache are returned as is.
+func Uncached(searcher Searcher) Searcher {
+	switch s := searcher.(type) {
+	case *CachingSearcher:
+		return Uncached(s.Searcher)
+	case *KnownSearcher:
+		uncached := *s
+		uncached.Searcher = Uncached(s.Searcher)
+		return &uncached
+	case *PrivateSearcher:
+		uncached := *s
+		uncached.Public = Uncached(s.Public)
+		uncached.Private = Uncached(s.Private)
+		return &uncached
+	}
+	return searcher
+}
+
+// path returns the cache file of the term, named after a hash of the backend and term.
+func (s *CachingSearcher) path(term string) string {
+	sum := sha256.Sum256([]byte(s.Backend + "\x00" + term))
+	return filepath.Join(s.Dir, searchCacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+func readCacheEntry(path string) (cacheEntry, error) {
+	var entry cacheEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	err = json.Unmarshal(data, &entry)
+	return entry, err
+}
+
+// writeCacheEntry writes the entry to a temporary file first so readers never see half a file.
+func writeCacheEntry(path string, entry cacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// CacheInfo describes the search results cached in a cache directory
+type CacheInfo struct {
+	Dir     string    `json:"dir"`
+	Entries int       `json:"entries"`
+	Size    int64     `json:"size"`
+	Oldest  time.Time `json:"oldest,omitempty"`
+	Newest  time.Time `json:"newest,omitempty"`
+}
+
+// SearchCacheInfo counts the cached search results in dir.
+func SearchCacheInfo(dir string) (CacheInfo, error) {
+	info := CacheInfo{Dir: dir}
+	files, err := os.ReadDir(filepath.Join(dir, searchCacheDir))
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+	if err != nil {
+		return info, fmt.Errorf("failed to read cache directory: %v", err)
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		fi, err := f.Info()
+		if err != nil {
+			continue
+		}
+		info.Entries++
+		info.Size += fi.Size()
+		if info.Oldest.IsZero() || fi.ModTime().Before(info.Oldest) {
+			info.Oldest = fi.ModTime()
+		}
+		if fi.ModTime().After(info.Newest) {
+			info.Newest = fi.ModTime()
+		}
+	}
+	return info, nil
+}
+
+// ClearSearchCache removes every cached search result in dir.
+func ClearSearchCache(dir string) error {
+	if err := os.RemoveAll(filepath.Join(dir, searchCacheDir)); err != nil {
+		return fmt.Errorf("failed to clear cache: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// countingSearcher counts the searches and fails with a network error when offline is set
+type countingSearcher struct {
+	calls   int
+	offline bool
+}
+
+func (s *countingSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	s.calls++
+	if s.offline {
+		return nil, NetworkError{URL: "fake://search", Err: errors.New("offline")}
+	}
+	return []SearchResult{{ImportPath: "github.com/example/" + term}}, nil
+}
+
+func TestCachingSearcher(t *testing.T) {
+	dir := t.TempDir()
+	backend := &countingSearcher{}
+	searcher := NewCachingSearcher(backend, "fake", dir, time.Hour)
+	ctx := context.Background()
+
+	t.Run("Fresh results are cached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			results, err := searcher.Search(ctx, "chi")
+			if err != nil || len(results) != 1 {
+				t.Fatalf("Expected one result, got %v, %v", results, err)
+			}
+		}
+		if backend.calls != 1 {
+			t.Errorf("Expected 1 search, got %d", backend.calls)
+		}
+	})
+
+	t.Run("Refresh searches again", func(t *testing.T) {
+		searcher.Refresh = true
+		defer func() { searcher.Refresh = false }()
+
+		if _, err := searcher.Search(ctx, "chi"); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if backend.calls != 2 {
+			t.Errorf("Expected 2 searches, got %d", backend.calls)
+		}
+	})
+
+	t.Run("Stale results when offline", func(t *testing.T) {
+		// expire the cached results
+		old := time.Now().Add(-2 * time.Hour)
+		if err := writeCacheEntry(searcher.path("chi"), cacheEntry{Backend: "fake", Term: "chi", Time: old, Results: []SearchResult{{ImportPath: "github.com/example/chi"}}}); err != nil {
+			t.Fatalf("writeCacheEntry failed: %v", err)
+		}
+		backend.offline = true
+		defer func() { backend.offline = false }()
+
+		results, err := searcher.Search(ctx, "chi")
+		var stale StaleResultsError
+		if !errors.As(err, &stale) {
+			t.Fatalf("Expected StaleResultsError, got %T: %v", err, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("Expected the cached result, got %v", results)
+		}
+
+		// nothing cached for this term
+		if _, err := searcher.Search(ctx, "cors"); errors.As(err, &stale) || err == nil {
+			t.Errorf("Expected NetworkError without cached results, got %v", err)
+		}
+	})
+
+	t.Run("Other backends are cached apart", func(t *testing.T) {
+		other := NewCachingSearcher(backend, "other", dir, time.Hour)
+		calls := backend.calls
+		if _, err := other.Search(ctx, "chi"); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if backend.calls != calls+1 {
+			t.Errorf("Expected the other backend to be searched")
+		}
+	})
+
+	t.Run("Info and clear", func(t *testing.T) {
+		info, err := SearchCacheInfo(dir)
+		if err != nil {
+			t.Fatalf("SearchCacheInfo failed: %v", err)
+		}
+		if info.Entries != 2 || info.Size == 0 {
+			t.Errorf("Expected 2 cached searches, got %+v", info)
+		}
+
+		if err := ClearSearchCache(dir); err != nil {
+			t.Fatalf("ClearSearchCache failed: %v", err)
+		}
+		info, err = SearchCacheInfo(dir)
+		if err != nil || info.Entries != 0 {
+			t.Errorf("Expected an empty cache, got %+v, %v", info, err)
+		}
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("Expected the cache directory to be kept, got %v", err)
+		}
+	})
+}
+
+func TestUncached(t *testing.T) {
+	dir := t.TempDir()
+	backend := &countingSearcher{}
+	known := []SearchResult{{ImportPath: "github.com/go-chi/chi/v5"}}
+	searcher := NewKnownSearcher(NewCachingSearcher(backend, "fake", dir, time.Hour), known)
+	ctx := context.Background()
+
+	uncached := Uncached(searcher)
+	if _, ok := uncached.(*KnownSearcher); !ok {
+		t.Fatalf("Expected the KnownSearcher to be kept, got %T", uncached)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := uncached.Search(ctx, "cors"); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+	}
+	if backend.calls != 2 {
+		t.Errorf("Expected 2 searches, got %d", backend.calls)
+	}
+	info, err := SearchCacheInfo(dir)
+	if err != nil || info.Entries != 0 {
+		t.Errorf("Expected nothing cached, got %+v, %v", info, err)
+	}
+
+	results, err := uncached.Search(ctx, "github.com/go-chi/chi/v5")
+	if err != nil || !reflect.DeepEqual(results, known) {
+		t.Errorf("Expected %v, got %v, %v", known, results, err)
+	}
+
+	// the searcher it was built from still caches
+	if _, err := searcher.Search(ctx, "cors"); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if info, err := SearchCacheInfo(dir); err != nil || info.Entries != 1 {
+		t.Errorf("Expected 1 cached search, got %+v, %v", info, err)
+	}
+}
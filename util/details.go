@@ -0,0 +1,217 @@
+package util
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// maxReadmeSize caps how much of a README is read, nobody scrolls further in a terminal
+const maxReadmeSize = 1 << 20
+
+// maxReadmeZipSize caps the module zip downloaded to read its README
+const maxReadmeZipSize = 32 << 20
+
+// readmeNames are the README file names looked for, in order of preference
+var readmeNames = []string{"readme.md", "readme.markdown", "readme", "readme.txt"}
+
+// PackageDetails is what is known about a search result before installing it
+type PackageDetails struct {
+	SearchResult
+	// LatestVersion and LatestTime are the latest release according to the module proxy,
+	// or the newest cached version when offline
+	LatestVersion string
+	LatestTime    time.Time
+	// Repository is the url of the source repository, empty when it can't be told
+	Repository string
+}
+
+// LoadDetails looks up the latest version of the module of r with the proxy client. The
+// details known from the search result are returned along with the error when the lookup fails.
+// When client is nil the module cache in dir is used instead, GOMODCACHE when dir is empty.
+func LoadDetails(ctx context.Context, client *ProxyClient, dir string, r SearchResult) (PackageDetails, error) {
+	details := PackageDetails{SearchResult: r, Repository: RepositoryURL(r.Module())}
+
+	if client == nil {
+		versions, err := ListCachedVersions(ctx, dir, r.Module())
+		if err != nil {
+			return details, err
+		}
+		if len(versions) > 0 {
+			details.LatestVersion = versions[0]
+		}
+		return details, nil
+	}
+
+	info, err := client.Latest(ctx, r.Module())
+	if err != nil {
+		return details, err
+	}
+	details.LatestVersion = info.Version
+	details.LatestTime = info.Time
+	if info.Origin != nil && info.Origin.URL != "" {
+		details.Repository = info.Origin.URL
+	}
+	return details, nil
+}
+
+// RepositoryURL infers the repository url from the module path for the well known code
+// hosts, it returns "" for the others.
+func RepositoryURL(modulePath string) string {
+	parts := strings.Split(modulePath, "/")
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org", "codeberg.org":
+		if len(parts) < 3 {
+			return ""
+		}
+		return "https://" + strings.Join(parts[:3], "/")
+	case "golang.org":
+		if len(parts) < 3 || parts[1] != "x" {
+			return ""
+		}
+		return "https://go.googlesource.com/" + parts[2]
+	}
+	return ""
+}
+
+// DocsURL returns the pkg.go.dev page of the module at version, the latest when version is empty.
+func DocsURL(modulePath string, version string) string {
+	return "https://pkg.go.dev/" + JoinVersion(modulePath, version)
+}
+
+// ModuleReadme returns the README at the root of the module at version. It is read from
+// the module cache in dir when the module is extracted there, GOMODCACHE when dir is empty,
+// and else from the zip served by the proxy client, which is not downloaded when larger than
+// maxReadmeZipSize. client may be nil to stay offline. The latest version is used when version is empty.
+func ModuleReadme(ctx context.Context, client *ProxyClient, dir string, modulePath string, version string) (string, error) {
+	if version == "" {
+		if client == nil {
+			return "", fmt.Errorf("no version of %s to read the README of", modulePath)
+		}
+		info, err := client.Latest(ctx, modulePath)
+		if err != nil {
+			return "", err
+		}
+		version = info.Version
+	}
+
+	if dir == "" {
+		dir, _ = ModCacheDir(ctx)
+	}
+	if dir != "" {
+		if readme, err := cachedReadme(dir, modulePath, version); err == nil {
+			return readme, nil
+		}
+	}
+	if client == nil {
+		return "", fmt.Errorf("no README of %s@%s in the module cache", modulePath, version)
+	}
+
+	f, err := client.Zip(ctx, modulePath, version, maxReadmeZipSize)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	return zipReadme(f, info.Size(), modulePath, version)
+}
+
+// cachedReadme reads the README of the module extracted in the module cache in dir.
+func cachedReadme(dir string, modulePath string, version string) (string, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", err
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(dir, filepath.FromSlash(escapedPath)+"@"+escapedVersion)
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	name, ok := pickReadme(names)
+	if !ok {
+		return "", fmt.Errorf("%s@%s has no README", modulePath, version)
+	}
+
+	f, err := os.Open(filepath.Join(root, name))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxReadmeSize))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// zipReadme reads the README at the root of a module zip, whose files are all
+// under the module@version/ directory.
+func zipReadme(data io.ReaderAt, size int64, modulePath string, version string) (string, error) {
+	r, err := zip.NewReader(data, size)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the zip of %s@%s: %v", modulePath, version, err)
+	}
+
+	prefix := modulePath + "@" + version + "/"
+	files := map[string]*zip.File{}
+	var names []string
+	for _, f := range r.File {
+		name := strings.TrimPrefix(f.Name, prefix)
+		if name == f.Name || strings.Contains(name, "/") {
+			// not at the root of the module
+			continue
+		}
+		files[name] = f
+		names = append(names, name)
+	}
+	name, ok := pickReadme(names)
+	if !ok {
+		return "", fmt.Errorf("%s@%s has no README", modulePath, version)
+	}
+
+	rc, err := files[name].Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the zip of %s@%s: %v", modulePath, version, err)
+	}
+	defer rc.Close()
+	readme, err := io.ReadAll(io.LimitReader(rc, maxReadmeSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the zip of %s@%s: %v", modulePath, version, err)
+	}
+	return string(readme), nil
+}
+
+// pickReadme returns the preferred README among the file names.
+func pickReadme(names []string) (string, bool) {
+	for _, readme := range readmeNames {
+		for _, name := range names {
+			if strings.ToLower(name) == readme {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
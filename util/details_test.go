@@ -0,0 +1,140 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepositoryURL(t *testing.T) {
+	tests := []struct {
+		modulePath string
+		expected   string
+	}{
+		{"github.com/spf13/cobra", "https://github.com/spf13/cobra"},
+		{"github.com/go-chi/chi/v5", "https://github.com/go-chi/chi"},
+		{"gitlab.com/group/project", "https://gitlab.com/group/project"},
+		{"golang.org/x/mod", "https://go.googlesource.com/mod"},
+		{"go.uber.org/zap", ""},
+		{"github.com/spf13", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.modulePath, func(t *testing.T) {
+			if got := RepositoryURL(test.modulePath); got != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestDocsURL(t *testing.T) {
+	if got := DocsURL("github.com/spf13/cobra", "v1.8.0"); got != "https://pkg.go.dev/github.com/spf13/cobra@v1.8.0" {
+		t.Errorf("Expected the versioned docs url, got %q", got)
+	}
+	if got := DocsURL("github.com/spf13/cobra", ""); got != "https://pkg.go.dev/github.com/spf13/cobra" {
+		t.Errorf("Expected the latest docs url, got %q", got)
+	}
+}
+
+func TestLoadDetails(t *testing.T) {
+	ctx := context.Background()
+	result := SearchResult{ImportPath: "github.com/spf13/cobra", Synopsis: "Package cobra is a commander"}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "github.com/spf13/cobra/@latest"), `{"Version":"v1.8.0","Time":"2023-11-05T00:00:00Z","Origin":{"VCS":"git","URL":"https://github.com/spf13/cobra.git"}}`)
+	client := NewProxyClient(ProxyEnv{GOPROXY: "file://" + filepath.ToSlash(dir)})
+
+	t.Run("From the proxy", func(t *testing.T) {
+		details, err := LoadDetails(ctx, client, "", result)
+		if err != nil {
+			t.Fatalf("LoadDetails failed: %v", err)
+		}
+		if details.LatestVersion != "v1.8.0" || details.LatestTime.IsZero() {
+			t.Errorf("Expected the latest version v1.8.0, got %q at %v", details.LatestVersion, details.LatestTime)
+		}
+		if details.Repository != "https://github.com/spf13/cobra.git" {
+			t.Errorf("Expected the origin url, got %q", details.Repository)
+		}
+		if details.Synopsis != result.Synopsis {
+			t.Errorf("Expected the synopsis of the search result, got %q", details.Synopsis)
+		}
+	})
+
+	t.Run("From the module cache", func(t *testing.T) {
+		modcache := t.TempDir()
+		writeFile(t, filepath.Join(modcache, "cache/download/github.com/spf13/cobra/@v/list"), "v1.7.0\nv1.8.0\n")
+
+		details, err := LoadDetails(ctx, nil, modcache, result)
+		if err != nil {
+			t.Fatalf("LoadDetails failed: %v", err)
+		}
+		if details.LatestVersion != "v1.8.0" {
+			t.Errorf("Expected the newest cached version v1.8.0, got %q", details.LatestVersion)
+		}
+		if details.Repository != "https://github.com/spf13/cobra" {
+			t.Errorf("Expected the inferred repository, got %q", details.Repository)
+		}
+	})
+
+	t.Run("Keeps the search result on failure", func(t *testing.T) {
+		details, err := LoadDetails(ctx, client, "", SearchResult{ImportPath: "github.com/example/missing", Synopsis: "Missing"})
+		if err == nil {
+			t.Fatal("Expected an error for an unknown module")
+		}
+		if details.Synopsis != "Missing" {
+			t.Errorf("Expected the synopsis of the search result, got %q", details.Synopsis)
+		}
+	})
+}
+
+func TestModuleReadme(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("From the module cache", func(t *testing.T) {
+		modcache := t.TempDir()
+		writeFile(t, filepath.Join(modcache, "github.com/!burnt!sushi/toml@v1.3.2/README.md"), "# TOML\n")
+		writeFile(t, filepath.Join(modcache, "github.com/!burnt!sushi/toml@v1.3.2/decode.go"), "package toml\n")
+
+		readme, err := ModuleReadme(ctx, nil, modcache, "github.com/BurntSushi/toml", "v1.3.2")
+		if err != nil || readme != "# TOML\n" {
+			t.Errorf("Expected the cached README, got %q, %v", readme, err)
+		}
+
+		if _, err := ModuleReadme(ctx, nil, modcache, "github.com/BurntSushi/toml", "v1.2.0"); err == nil {
+			t.Errorf("Expected an error for a version that is not cached")
+		}
+	})
+
+	t.Run("From the proxy zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range map[string]string{
+			"github.com/spf13/cobra@v1.8.0/cobra.go":        "package cobra\n",
+			"github.com/spf13/cobra@v1.8.0/doc/README.md":   "# Docs\n",
+			"github.com/spf13/cobra@v1.8.0/README.md":       "# Cobra\n",
+			"github.com/spf13/cobra@v1.8.0/readme.markdown": "# Other\n",
+		} {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			w.Write([]byte(content))
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "github.com/spf13/cobra/@v/v1.8.0.zip"), buf.String())
+		writeFile(t, filepath.Join(dir, "github.com/spf13/cobra/@latest"), `{"Version":"v1.8.0"}`)
+		client := NewProxyClient(ProxyEnv{GOPROXY: "file://" + filepath.ToSlash(dir)})
+
+		readme, err := ModuleReadme(ctx, client, t.TempDir(), "github.com/spf13/cobra", "")
+		if err != nil || readme != "# Cobra\n" {
+			t.Errorf("Expected the README at the root, got %q, %v", readme, err)
+		}
+	})
+}
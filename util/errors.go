@@ -0,0 +1,138 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NetworkError is returned when a search backend could not be reached.
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e NetworkError) Error() string {
+	return fmt.Sprintf("could not reach %s: %v", e.URL, e.Err)
+}
+
+func (e NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned when a search backend answers with an unexpected status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%s answered with HTTP %d", e.URL, e.StatusCode)
+}
+
+// RateLimitError is returned when a search backend rejects the request for being sent too often.
+type RateLimitError struct {
+	URL string
+	// RetryAfter is how long the backend asked to wait, zero if it did not say
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by %s, retry in %s", e.URL, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited by %s", e.URL)
+}
+
+// ParseError is returned when the response of a search backend can not be understood.
+type ParseError struct {
+	URL string
+	Err error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("could not parse response from %s: %v", e.URL, e.Err)
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// GoCommandError is returned when the go command fails, it carries what the command wrote to stderr.
+type GoCommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e GoCommandError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("go %s: %v", strings.Join(e.Args, " "), e.Err)
+	}
+	return fmt.Sprintf("go %s: %v\n%s", strings.Join(e.Args, " "), e.Err, e.Stderr)
+}
+
+func (e GoCommandError) Unwrap() error {
+	return e.Err
+}
+
+// AuthError is returned when a private index, module proxy or code host rejected the credentials.
+type AuthError struct {
+	// Host asked for the credentials, empty when the go command failed
+	Host string
+	Err  error
+}
+
+func (e AuthError) Error() string {
+	if e.Host != "" {
+		return fmt.Sprintf("authentication failed for %s, check its credentials in your netrc file: %v", e.Host, e.Err)
+	}
+	return fmt.Sprintf("authentication failed, check your netrc file and git credentials: %v", e.Err)
+}
+
+func (e AuthError) Unwrap() error {
+	return e.Err
+}
+
+// RequestTimeout bounds every request to a search backend or module proxy.
+const RequestTimeout = 30 * time.Second
+
+// httpClient is the client used by default, it gives up after RequestTimeout.
+var httpClient = &http.Client{Timeout: RequestTimeout}
+
+// get sends a GET request that is cancelled along with ctx. The credentials of the machine
+// entry of the host in the netrc file are sent when the url doesn't carry any. Like the go
+// command, they only go over https, never in the clear.
+func get(ctx context.Context, client *http.Client, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme == "https" && req.URL.User == nil {
+		if login, password, ok := netrcCredentials(req.URL.Hostname()); ok {
+			req.SetBasicAuth(login, password)
+		}
+	}
+	return client.Do(req)
+}
+
+// checkResponse turns a non-200 response into a StatusError, RateLimitError or AuthError.
+func checkResponse(u string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return AuthError{Host: resp.Request.URL.Host, Err: StatusError{URL: u, StatusCode: resp.StatusCode}}
+	case http.StatusTooManyRequests:
+		var retryAfter time.Duration
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return RateLimitError{URL: u, RetryAfter: retryAfter}
+	default:
+		return StatusError{URL: u, StatusCode: resp.StatusCode}
+	}
+}
@@ -0,0 +1,137 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// GoCommandTimeout bounds how long a go command may run, downloading a large module graph can be slow.
+const GoCommandTimeout = 10 * time.Minute
+
+// GoRunner runs go commands. The zero value runs them quietly, what the command
+// writes to stderr is only kept for the GoCommandError when it fails.
+type GoRunner struct {
+	// Output receives what the command writes to stderr while it runs
+	Output io.Writer
+	// Env is added to the environment of the command, e.g. OfflineEnv
+	Env []string
+}
+
+// NewGoRunner returns a GoRunner that only uses the module cache when offline is true.
+func NewGoRunner(offline bool) GoRunner {
+	if offline {
+		return GoRunner{Env: OfflineEnv}
+	}
+	return GoRunner{}
+}
+
+// Get runs 'go get' for the packages, resolving them together.
+func (r GoRunner) Get(ctx context.Context, pkgs ...string) error {
+	return r.Run(ctx, append([]string{"get"}, pkgs...)...)
+}
+
+// GetAll adds the packages with a single 'go get' so the module graph is resolved once.
+// When that fails every package is retried on its own to find the broken ones. The errors
+// are in the same order as pkgs, nil for the packages that were added.
+func (r GoRunner) GetAll(ctx context.Context, pkgs []string) []error {
+	errs := make([]error, len(pkgs))
+	if len(pkgs) == 0 {
+		return errs
+	}
+
+	err := r.Get(ctx, pkgs...)
+	if err == nil {
+		return errs
+	}
+	// no point in retrying a single package or a cancelled command
+	if len(pkgs) == 1 || ctx.Err() != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	for i, pkg := range pkgs {
+		errs[i] = r.Get(ctx, pkg)
+	}
+	return errs
+}
+
+// Install runs 'go install' for the package, which must carry a version.
+func (r GoRunner) Install(ctx context.Context, pkg string) error {
+	return r.Run(ctx, "install", pkg)
+}
+
+// Run runs the go command with the arguments, capturing its stderr in a GoCommandError when it fails.
+// The command is killed when ctx is done or after GoCommandTimeout.
+func (r GoRunner) Run(ctx context.Context, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, GoCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Env = goEnv(r.Env)
+	cmd.Stderr = &stderr
+	if r.Output != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, r.Output)
+	}
+	if err := cmd.Run(); err != nil {
+		return commandError(args, stderr.String(), err)
+	}
+	return nil
+}
+
+// goEnv returns the environment for go commands with env added. Git is kept from prompting
+// for credentials, nobody would see the prompt, the command fails with an AuthError instead.
+func goEnv(env []string) []string {
+	environ := os.Environ()
+	if os.Getenv("GIT_TERMINAL_PROMPT") == "" {
+		environ = append(environ, "GIT_TERMINAL_PROMPT=0")
+	}
+	return append(environ, env...)
+}
+
+// authFailures are written by git and the go command when credentials are missing or wrong
+var authFailures = []string{
+	"terminal prompts disabled",
+	"could not read Username",
+	"could not read Password",
+	"Authentication failed",
+	"Permission denied (publickey)",
+	"401 Unauthorized",
+	"403 Forbidden",
+}
+
+// commandError wraps the failure of a go command in a GoCommandError, or an AuthError
+// when the output shows the credentials were rejected.
+func commandError(args []string, stderr string, err error) error {
+	goErr := GoCommandError{Args: args, Stderr: strings.TrimSpace(stderr), Err: err}
+	for _, failure := range authFailures {
+		if strings.Contains(stderr, failure) {
+			return AuthError{Err: goErr}
+		}
+	}
+	return goErr
+}
+
+// goOutput runs the go command and returns what it writes to stdout, it is killed
+// when ctx is done or after GoCommandTimeout.
+func goOutput(ctx context.Context, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, GoCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Env = goEnv(nil)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(args, stderr.String(), err)
+	}
+	return output, nil
+}
@@ -0,0 +1,28 @@
+package util
+
+import "context"
+
+// FavoritesQuery is the query that stands for every starred package
+const FavoritesQuery = "@fav"
+
+// KnownSearcher answers the import paths of packages known ahead, like the starred packages
+// or the ones in bundles, without searching. The other terms go to Searcher.
+type KnownSearcher struct {
+	Searcher Searcher
+	known    map[string]SearchResult
+}
+
+func NewKnownSearcher(searcher Searcher, known []SearchResult) *KnownSearcher {
+	byPath := make(map[string]SearchResult, len(known))
+	for _, r := range known {
+		byPath[r.ImportPath] = r
+	}
+	return &KnownSearcher{Searcher: searcher, known: byPath}
+}
+
+func (s *KnownSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	if r, ok := s.known[term]; ok {
+		return []SearchResult{r}, nil
+	}
+	return s.Searcher.Search(ctx, term)
+}
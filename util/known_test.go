@@ -0,0 +1,27 @@
+package util
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKnownSearcher(t *testing.T) {
+	backend := &countingSearcher{}
+	searcher := NewKnownSearcher(backend, []SearchResult{{ImportPath: "github.com/go-chi/chi/v5", Synopsis: "Starred"}})
+	ctx := context.Background()
+
+	results, err := searcher.Search(ctx, "github.com/go-chi/chi/v5")
+	if err != nil || len(results) != 1 || results[0].Synopsis != "Starred" {
+		t.Errorf("Expected the known package, got %v, %v", results, err)
+	}
+	if backend.calls != 0 {
+		t.Errorf("Expected no search for a known package, got %d", backend.calls)
+	}
+
+	if _, err := searcher.Search(ctx, "cors"); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if backend.calls != 1 {
+		t.Errorf("Expected 1 search, got %d", backend.calls)
+	}
+}
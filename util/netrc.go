@@ -0,0 +1,83 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// netrcPath returns the netrc file the go command reads, $NETRC or ~/.netrc (~/_netrc on Windows).
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(home, name)
+}
+
+// netrcCredentials returns the login and password of the machine entry of the host in the netrc file.
+func netrcCredentials(host string) (login string, password string, ok bool) {
+	path := netrcPath()
+	if path == "" {
+		return "", "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	return parseNetrc(string(data), host)
+}
+
+// parseNetrc finds the machine entry of the host in the contents of a netrc file. Like the go
+// command, the default entry is ignored, it would send the credentials to every host.
+func parseNetrc(data string, host string) (login string, password string, ok bool) {
+	var machine string
+
+	// match reports whether the entry that just ended is the one we look for
+	match := func() bool {
+		return machine == host && (login != "" || password != "")
+	}
+
+	fields := strings.Fields(data)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if match() {
+				return login, password, true
+			}
+			machine, login, password = "", "", ""
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "login":
+			if i+1 < len(fields) {
+				i++
+				login = fields[i]
+			}
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				password = fields[i]
+			}
+		case "default", "macdef":
+			// the default entry comes last and macros run until a blank line, nothing after them is read
+			if match() {
+				return login, password, true
+			}
+			return "", "", false
+		}
+	}
+	if match() {
+		return login, password, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,285 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// SourceOffline is the name of the search backend that searches the local module cache.
+const SourceOffline = "offline"
+
+// maxOfflineResults caps the results of an offline search like pkg.go.dev does
+const maxOfflineResults = 25
+
+// OfflineEnv makes go commands resolve modules from the module cache only. Without a
+// proxy the go command can't resolve queries like latest, the versions must be pinned.
+var OfflineEnv = []string{"GOFLAGS=-mod=mod", "GOPROXY=off"}
+
+// ModCacheDir returns the module cache directory, GOMODCACHE.
+func ModCacheDir(ctx context.Context) (string, error) {
+	output, err := goOutput(ctx, "env", "GOMODCACHE")
+	if err != nil {
+		return "", fmt.Errorf("error executing command: %v", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if dir == "" {
+		return "", fmt.Errorf("GOMODCACHE is not set")
+	}
+	return dir, nil
+}
+
+// OfflineSearcher searches the packages of the modules extracted in the module cache.
+// Only the newest cached version of each module is indexed, the index is built on the
+// first search.
+type OfflineSearcher struct {
+	// Dir is the module cache directory, GOMODCACHE when empty
+	Dir string
+
+	mu      sync.Mutex
+	indexed bool
+	index   []SearchResult
+	err     error
+}
+
+func NewOfflineSearcher(dir string) *OfflineSearcher {
+	return &OfflineSearcher{Dir: dir}
+}
+
+// buildIndex indexes the module cache on the first call. An index cancelled with the search
+// that asked for it isn't kept, the next search builds it again.
+func (s *OfflineSearcher) buildIndex(ctx context.Context) ([]SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.indexed {
+		return s.index, s.err
+	}
+
+	dir := s.Dir
+	var err error
+	if dir == "" {
+		dir, err = ModCacheDir(ctx)
+	}
+	var index []SearchResult
+	if err == nil {
+		index, err = indexModCache(ctx, dir)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	s.indexed, s.index, s.err = true, index, err
+	return index, err
+}
+
+func (s *OfflineSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	index, err := s.buildIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	term = strings.ToLower(strings.TrimSpace(term))
+	type match struct {
+		result SearchResult
+		score  int
+	}
+	var matches []match
+	for _, r := range index {
+		if score, ok := offlineScore(term, r); ok {
+			matches = append(matches, match{result: r, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return matches[i].result.ImportPath < matches[j].result.ImportPath
+	})
+
+	results := make([]SearchResult, 0, maxOfflineResults)
+	for _, m := range matches {
+		if len(results) == maxOfflineResults {
+			break
+		}
+		results = append(results, m.result)
+	}
+	return results, nil
+}
+
+// offlineScore ranks how well the package matches the term, lower is better.
+func offlineScore(term string, r SearchResult) (int, bool) {
+	importPath := strings.ToLower(r.ImportPath)
+	switch {
+	case importPath == term:
+		return 0, true
+	case path.Base(importPath) == term || path.Base(strings.ToLower(r.ModulePath)) == term:
+		return 1, true
+	case strings.Contains(importPath, term):
+		return 2, true
+	case strings.Contains(strings.ToLower(r.Synopsis), term):
+		return 3, true
+	}
+	return 0, false
+}
+
+// indexModCache lists the packages of the newest cached version of every module in dir.
+func indexModCache(ctx context.Context, dir string) ([]SearchResult, error) {
+	latest := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// unreadable directories are skipped
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.IsDir() || p == dir {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if rel == "cache" {
+			// downloaded zips, not extracted modules
+			return filepath.SkipDir
+		}
+		at := strings.LastIndex(d.Name(), "@")
+		if at < 0 {
+			return nil
+		}
+
+		escaped := filepath.ToSlash(rel[:len(rel)-len(d.Name())+at])
+		modulePath, err := module.UnescapePath(escaped)
+		if err == nil {
+			version := d.Name()[at+1:]
+			if current, ok := latest[modulePath]; !ok || semver.Compare(version, current) > 0 {
+				latest[modulePath] = version
+			}
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the module cache: %v", err)
+	}
+
+	var index []SearchResult
+	for modulePath, version := range latest {
+		escaped, err := module.EscapePath(modulePath)
+		if err != nil {
+			continue
+		}
+		root := filepath.Join(dir, filepath.FromSlash(escaped)+"@"+version)
+		index = append(index, modulePackages(root, modulePath, version)...)
+	}
+	return index, nil
+}
+
+// modulePackages lists the importable packages of the module extracted in root.
+func modulePackages(root string, modulePath string, version string) []SearchResult {
+	var packages []SearchResult
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if p != root {
+			if name == "testdata" || name == "vendor" || name == "internal" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				// a nested module
+				return filepath.SkipDir
+			}
+		}
+
+		synopsis, ok := packageSynopsis(p)
+		if !ok {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		importPath := modulePath
+		if rel != "." {
+			importPath = path.Join(modulePath, filepath.ToSlash(rel))
+		}
+		packages = append(packages, SearchResult{
+			ImportPath: importPath,
+			ModulePath: modulePath,
+			Version:    version,
+			Synopsis:   synopsis,
+		})
+		return nil
+	})
+	return packages
+}
+
+// packageSynopsis returns the first sentence of the package doc comment in dir. ok is
+// false when dir holds no go files.
+func packageSynopsis(dir string) (synopsis string, ok bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	fset := token.NewFileSet()
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		ok = true
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || f.Doc == nil {
+			continue
+		}
+		return new(doc.Package).Synopsis(f.Doc.Text()), true
+	}
+	return "", ok
+}
+
+// ListCachedVersions returns the versions of a module downloaded to the module cache in dir,
+// newest first. GOMODCACHE is used when dir is empty.
+func ListCachedVersions(ctx context.Context, dir string, modulePath string) ([]string, error) {
+	if dir == "" {
+		var err error
+		if dir, err = ModCacheDir(ctx); err != nil {
+			return nil, err
+		}
+	}
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, "cache", "download", filepath.FromSlash(escaped), "@v", "list"))
+	if err != nil {
+		return nil, fmt.Errorf("no cached versions of %s: %v", modulePath, err)
+	}
+	defer f.Close()
+
+	var versions []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v := strings.TrimSpace(scanner.Text()); semver.IsValid(v) {
+			versions = append(versions, v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cached versions: %v", err)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i], versions[j]) > 0
+	})
+	return versions, nil
+}
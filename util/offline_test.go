@@ -0,0 +1,89 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile creates the file and its parent directories
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOfflineSearcher(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml@v1.3.2/decode.go"), "// Package toml implements decoding and encoding of TOML files.\npackage toml\n")
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml@v1.3.2/internal/tz.go"), "package internal\n")
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml@v1.3.2/cmd/tomlv/main.go"), "// Command tomlv validates TOML files.\npackage main\n")
+	writeFile(t, filepath.Join(dir, "github.com/go-chi/chi/v5@v5.0.10/chi.go"), "// Package chi is a small router.\npackage chi\n")
+	writeFile(t, filepath.Join(dir, "github.com/go-chi/chi/v5@v5.0.12/chi.go"), "// Package chi is a lightweight router.\npackage chi\n")
+	writeFile(t, filepath.Join(dir, "cache/download/github.com/go-chi/chi/v5/@v/list"), "v5.0.10\nv5.0.12\n")
+
+	searcher := NewOfflineSearcher(dir)
+
+	t.Run("Cancelled index is built again", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := searcher.Search(ctx, "toml"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		results, err := searcher.Search(context.Background(), "toml")
+		if err != nil || len(results) == 0 {
+			t.Errorf("Expected results after the cancelled search, got %v, %v", results, err)
+		}
+	})
+
+	t.Run("Packages and synopses", func(t *testing.T) {
+		results, err := searcher.Search(context.Background(), "toml")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		expected := []SearchResult{
+			{ImportPath: "github.com/BurntSushi/toml", ModulePath: "github.com/BurntSushi/toml", Version: "v1.3.2", Synopsis: "Package toml implements decoding and encoding of TOML files."},
+			{ImportPath: "github.com/BurntSushi/toml/cmd/tomlv", ModulePath: "github.com/BurntSushi/toml", Version: "v1.3.2", Synopsis: "Command tomlv validates TOML files."},
+		}
+		if !reflect.DeepEqual(results, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, results)
+		}
+	})
+
+	t.Run("Newest cached version", func(t *testing.T) {
+		results, err := searcher.Search(context.Background(), "chi")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].ImportPath != "github.com/go-chi/chi/v5" || results[0].Version != "v5.0.12" {
+			t.Errorf("Expected github.com/go-chi/chi/v5 v5.0.12, got %+v", results)
+		}
+	})
+
+	t.Run("Synopsis match", func(t *testing.T) {
+		results, err := searcher.Search(context.Background(), "router")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].ImportPath != "github.com/go-chi/chi/v5" {
+			t.Errorf("Expected github.com/go-chi/chi/v5, got %+v", results)
+		}
+	})
+
+	t.Run("Cached versions", func(t *testing.T) {
+		versions, err := ListCachedVersions(context.Background(), dir, "github.com/go-chi/chi/v5")
+		if err != nil {
+			t.Fatalf("ListCachedVersions failed: %v", err)
+		}
+		if !reflect.DeepEqual(versions, []string{"v5.0.12", "v5.0.10"}) {
+			t.Errorf("Expected [v5.0.12 v5.0.10], got %v", versions)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/mod/module"
+)
+
+// PrivateSearcher sends the queries matching the GOPRIVATE patterns to a private index
+// instead of the public searcher, they would never be found there. The query itself is
+// always the first result, private modules are usually installed by their import path.
+type PrivateSearcher struct {
+	Public Searcher
+	// Private is the index of the private modules, nil when there is none
+	Private Searcher
+	// Patterns are the GOPRIVATE patterns
+	Patterns string
+}
+
+func NewPrivateSearcher(public Searcher, private Searcher, patterns string) *PrivateSearcher {
+	return &PrivateSearcher{
+		Public:   public,
+		Private:  private,
+		Patterns: patterns,
+	}
+}
+
+// IsPrivate reports whether the import path matches the GOPRIVATE patterns.
+func (s *PrivateSearcher) IsPrivate(importPath string) bool {
+	return s.Patterns != "" && module.MatchPrefixPatterns(s.Patterns, importPath)
+}
+
+func (s *PrivateSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	if !s.IsPrivate(term) {
+		return s.Public.Search(ctx, term)
+	}
+
+	direct := SearchResult{ImportPath: term, Synopsis: "Private module, installed by its import path"}
+	if s.Private == nil {
+		return []SearchResult{direct}, nil
+	}
+
+	found, err := s.Private.Search(ctx, term)
+	if err != nil {
+		return nil, err
+	}
+	results := []SearchResult{direct}
+	for _, r := range found {
+		if r.ImportPath == term {
+			// the index knows more about it
+			results[0] = r
+			continue
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// listVersionsDirect lists the versions of a module with the go command, which fetches
+// them from the code host with the git credentials and the netrc file.
+func listVersionsDirect(ctx context.Context, modulePath string) ([]string, error) {
+	output, err := goOutput(ctx, "list", "-m", "-versions", "-json", modulePath)
+	if err != nil {
+		var authErr AuthError
+		if errors.As(err, &authErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error listing versions of %s: %v", modulePath, err)
+	}
+
+	var info struct {
+		Versions []string
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	// the go command lists them oldest first
+	versions := make([]string, len(info.Versions))
+	for i, v := range info.Versions {
+		versions[len(versions)-1-i] = v
+	}
+	return versions, nil
+}
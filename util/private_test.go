@@ -0,0 +1,153 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrivateSearcher(t *testing.T) {
+	public := &countingSearcher{}
+	private := fakeIndex{
+		"git.corp.example.com/platform/auth": {
+			{ImportPath: "git.corp.example.com/platform/auth", Synopsis: "Package auth checks tokens."},
+			{ImportPath: "git.corp.example.com/platform/auth/jwt"},
+		},
+	}
+	searcher := NewPrivateSearcher(public, private, "git.corp.example.com,*.internal.example.com")
+	ctx := context.Background()
+
+	t.Run("Public queries", func(t *testing.T) {
+		if _, err := searcher.Search(ctx, "chi"); err != nil || public.calls != 1 {
+			t.Errorf("Expected the public searcher to be used, got %d calls, %v", public.calls, err)
+		}
+	})
+
+	t.Run("Private index", func(t *testing.T) {
+		results, err := searcher.Search(ctx, "git.corp.example.com/platform/auth")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 2 || results[0].Synopsis != "Package auth checks tokens." {
+			t.Errorf("Expected the index results, got %+v", results)
+		}
+	})
+
+	t.Run("Import path without index", func(t *testing.T) {
+		searcher := NewPrivateSearcher(public, nil, "*.internal.example.com")
+		results, err := searcher.Search(ctx, "code.internal.example.com/tools/gen")
+		if err != nil || len(results) != 1 || results[0].ImportPath != "code.internal.example.com/tools/gen" {
+			t.Errorf("Expected the query as import path, got %+v, %v", results, err)
+		}
+		if public.calls != 1 {
+			t.Errorf("Expected private queries to skip the public searcher")
+		}
+	})
+}
+
+// fakeIndex answers searches from a map
+type fakeIndex map[string][]SearchResult
+
+func (s fakeIndex) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	return s[term], nil
+}
+
+func TestParseNetrc(t *testing.T) {
+	netrc := `machine git.corp.example.com
+  login alice
+  password s3cret
+
+machine proxy.corp.example.com login bot password token
+
+default login anonymous password guest
+`
+	tests := []struct {
+		host     string
+		login    string
+		password string
+		ok       bool
+	}{
+		{"git.corp.example.com", "alice", "s3cret", true},
+		{"proxy.corp.example.com", "bot", "token", true},
+		// the default entry is never used, like the go command does
+		{"other.example.com", "", "", false},
+	}
+
+	for _, tt := range tests {
+		login, password, ok := parseNetrc(netrc, tt.host)
+		if login != tt.login || password != tt.password || ok != tt.ok {
+			t.Errorf("parseNetrc(%s): expected (%s, %s, %v), got (%s, %s, %v)", tt.host, tt.login, tt.password, tt.ok, login, password, ok)
+		}
+	}
+
+	if _, _, ok := parseNetrc("machine a login x password y", "b"); ok {
+		t.Error("Expected no credentials for a host without an entry")
+	}
+	if _, _, ok := parseNetrc("default login x password y\nmachine b login z password w", "b"); ok {
+		t.Error("Expected nothing to be read after the default entry")
+	}
+}
+
+func TestNetrcAuth(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"path": "git.corp.example.com/platform/auth"}]`))
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	netrc := filepath.Join(t.TempDir(), ".netrc")
+	searcher := NewIndexSearcher(server.URL)
+	searcher.Client = server.Client()
+
+	// without credentials
+	t.Setenv("NETRC", netrc)
+	_, err := searcher.Search(context.Background(), "auth")
+	var authErr AuthError
+	if !errors.As(err, &authErr) || authErr.Host != u.Host {
+		t.Fatalf("Expected AuthError for %s, got %T: %v", u.Host, err, err)
+	}
+
+	// with the credentials of the host
+	if err := os.WriteFile(netrc, []byte("machine "+u.Hostname()+" login alice password s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	results, err := searcher.Search(context.Background(), "auth")
+	if err != nil || len(results) != 1 {
+		t.Errorf("Expected one result with credentials, got %v, %v", results, err)
+	}
+
+	// the credentials are never sent in the clear
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+	_, err = NewIndexSearcher(plain.URL).Search(context.Background(), "auth")
+	if !errors.As(err, &authErr) {
+		t.Errorf("Expected AuthError over http, got %T: %v", err, err)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	err := commandError([]string{"get", "git.corp.example.com/auth"}, "fatal: could not read Username for 'https://git.corp.example.com': terminal prompts disabled", errors.New("exit status 1"))
+	var authErr AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Expected AuthError, got %T: %v", err, err)
+	}
+	var goErr GoCommandError
+	if !errors.As(err, &goErr) {
+		t.Errorf("Expected AuthError to wrap the GoCommandError")
+	}
+
+	err = commandError([]string{"get", "example.com/missing"}, "go: module example.com/missing: not found", errors.New("exit status 1"))
+	if errors.As(err, &authErr) {
+		t.Errorf("Expected a plain GoCommandError, got %v", err)
+	}
+}
@@ -0,0 +1,355 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// ProxyEnv holds the go environment that decides how modules are looked up
+type ProxyEnv struct {
+	GOPROXY   string
+	GONOPROXY string
+	GOPRIVATE string
+}
+
+// LoadProxyEnv reads the proxy settings with 'go env', so values written with 'go env -w'
+// are honored as well as environment variables.
+func LoadProxyEnv(ctx context.Context) (ProxyEnv, error) {
+	var env ProxyEnv
+	output, err := goOutput(ctx, "env", "-json", "GOPROXY", "GONOPROXY", "GOPRIVATE")
+	if err != nil {
+		return env, fmt.Errorf("error executing command: %v", err)
+	}
+	if err := json.Unmarshal(output, &env); err != nil {
+		return env, fmt.Errorf("error parsing JSON: %v", err)
+	}
+	return env, nil
+}
+
+// proxyEntry is one of the proxies in GOPROXY
+type proxyEntry struct {
+	// url is the proxy url, or "direct" or "off"
+	url string
+	// fallbackOnError is true when the proxy is followed by '|', the next proxy is tried
+	// on any error instead of only when the module is not found
+	fallbackOnError bool
+}
+
+// ProxyClient looks up module metadata with the module proxy protocol. It supports
+// http(s) and file:// proxies. Modules matching GONOPROXY or GOPRIVATE are never looked
+// up through a proxy. Like the go command, the TLS certificate of a proxy is always verified.
+type ProxyClient struct {
+	proxies []proxyEntry
+	noProxy string
+	Client  *http.Client
+}
+
+// NewProxyClient builds a client for the proxy settings. GONOPROXY defaults to GOPRIVATE
+// like the go command does.
+func NewProxyClient(env ProxyEnv) *ProxyClient {
+	goproxy := env.GOPROXY
+	if goproxy == "" {
+		goproxy = DEFAULT_PROXY_URL + ",direct"
+	}
+	noProxy := env.GONOPROXY
+	if noProxy == "" {
+		noProxy = env.GOPRIVATE
+	}
+
+	var proxies []proxyEntry
+	for goproxy != "" {
+		var entry proxyEntry
+		i := strings.IndexAny(goproxy, ",|")
+		if i < 0 {
+			entry.url, goproxy = goproxy, ""
+		} else {
+			entry.url, entry.fallbackOnError, goproxy = goproxy[:i], goproxy[i] == '|', goproxy[i+1:]
+		}
+		entry.url = strings.TrimSuffix(strings.TrimSpace(entry.url), "/")
+		if entry.url != "" {
+			proxies = append(proxies, entry)
+		}
+	}
+
+	return &ProxyClient{
+		proxies: proxies,
+		noProxy: noProxy,
+		Client:  httpClient,
+	}
+}
+
+// NewProxyClientFromEnv builds a client for the proxy settings of the go environment.
+func NewProxyClientFromEnv(ctx context.Context) (*ProxyClient, error) {
+	env, err := LoadProxyEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewProxyClient(env), nil
+}
+
+// ModuleInfo is the answer of the .info and @latest endpoints
+type ModuleInfo struct {
+	Version string
+	Time    time.Time
+	// Origin is where the proxy got the module from, not every proxy reports it
+	Origin *ModuleOrigin `json:",omitempty"`
+}
+
+// ModuleOrigin is the version control origin of a module version
+type ModuleOrigin struct {
+	VCS  string
+	URL  string
+	Ref  string
+	Hash string
+}
+
+// NoProxyError is returned for modules that can't be looked up through a proxy,
+// because of GOPROXY=off, GONOPROXY or GOPRIVATE, or a proxy list that ends in direct.
+type NoProxyError struct {
+	ModulePath string
+	Reason     string
+}
+
+func (e NoProxyError) Error() string {
+	return fmt.Sprintf("can't look up %s through a module proxy: %s", e.ModulePath, e.Reason)
+}
+
+// notFoundError is returned when a proxy doesn't know the module or version,
+// the next proxy in the list is tried
+type notFoundError struct {
+	err error
+}
+
+func (e notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e notFoundError) Unwrap() error {
+	return e.err
+}
+
+// List returns the released versions of the module, newest first.
+func (c *ProxyClient) List(ctx context.Context, modulePath string) ([]string, error) {
+	data, err := c.fetch(ctx, modulePath, "@v/list")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		// lines may carry more fields after the version
+		fields := strings.Fields(line)
+		if len(fields) > 0 && semver.IsValid(fields[0]) {
+			versions = append(versions, fields[0])
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare(versions[i], versions[j]) > 0
+	})
+	return versions, nil
+}
+
+// Latest returns the latest version of the module, which may be a pseudo-version when
+// the module has no releases.
+func (c *ProxyClient) Latest(ctx context.Context, modulePath string) (ModuleInfo, error) {
+	return c.info(ctx, modulePath, "@latest")
+}
+
+// Info returns the canonical version and time of a version of the module.
+func (c *ProxyClient) Info(ctx context.Context, modulePath string, version string) (ModuleInfo, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return ModuleInfo{}, err
+	}
+	return c.info(ctx, modulePath, "@v/"+escaped+".info")
+}
+
+// GoMod returns the go.mod file of a version of the module.
+func (c *ProxyClient) GoMod(ctx context.Context, modulePath string, version string) ([]byte, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return c.fetch(ctx, modulePath, "@v/"+escaped+".mod")
+}
+
+// ZipTooLargeError is returned for a module zip larger than the caller is willing to download
+type ZipTooLargeError struct {
+	ModulePath string
+	Version    string
+	MaxSize    int64
+}
+
+func (e ZipTooLargeError) Error() string {
+	return fmt.Sprintf("the zip of %s@%s is larger than %d MB", e.ModulePath, e.Version, e.MaxSize>>20)
+}
+
+// Zip downloads the zip archive of the module source at a version to a temporary file,
+// module zips can be hundreds of MB. It fails with a ZipTooLargeError without reading
+// more than maxSize bytes. The caller closes and removes the file.
+func (c *ProxyClient) Zip(ctx context.Context, modulePath string, version string, maxSize int64) (*os.File, error) {
+	escaped, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.open(ctx, modulePath, "@v/"+escaped+".zip")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	tooLarge := ZipTooLargeError{ModulePath: modulePath, Version: version, MaxSize: maxSize}
+	if body.size > maxSize {
+		return nil, tooLarge
+	}
+
+	f, err := os.CreateTemp("", "gopack-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary file: %v", err)
+	}
+	// the size is unknown for chunked responses, stop reading one byte after the limit
+	n, err := io.Copy(f, io.LimitReader(body, maxSize+1))
+	switch {
+	case err != nil:
+		err = NetworkError{URL: body.url, Err: err}
+	case n > maxSize:
+		err = tooLarge
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c *ProxyClient) info(ctx context.Context, modulePath string, endpoint string) (ModuleInfo, error) {
+	var info ModuleInfo
+	data, err := c.fetch(ctx, modulePath, endpoint)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, ParseError{URL: endpoint, Err: err}
+	}
+	return info, nil
+}
+
+// proxyBody is the body of an endpoint of a proxy, size is -1 when unknown
+type proxyBody struct {
+	io.ReadCloser
+	url  string
+	size int64
+}
+
+// fetch reads the endpoint of the module from the proxies.
+func (c *ProxyClient) fetch(ctx context.Context, modulePath string, endpoint string) ([]byte, error) {
+	body, err := c.open(ctx, modulePath, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, NetworkError{URL: body.url, Err: err}
+	}
+	return data, nil
+}
+
+// open opens the endpoint of the module from the proxies in order, moving on to the next
+// one when the module is not found, or on any error after a '|'.
+func (c *ProxyClient) open(ctx context.Context, modulePath string, endpoint string) (proxyBody, error) {
+	if module.MatchPrefixPatterns(c.noProxy, modulePath) {
+		return proxyBody{}, NoProxyError{ModulePath: modulePath, Reason: "it matches GONOPROXY or GOPRIVATE"}
+	}
+	escaped, err := module.EscapePath(modulePath)
+	if err != nil {
+		return proxyBody{}, err
+	}
+
+	var lastErr error
+	for _, p := range c.proxies {
+		switch p.url {
+		case "off":
+			return proxyBody{}, orNoProxy(lastErr, NoProxyError{ModulePath: modulePath, Reason: "GOPROXY=off"})
+		case "direct":
+			// fetching from the version control system is left to the go command
+			return proxyBody{}, orNoProxy(lastErr, NoProxyError{ModulePath: modulePath, Reason: "GOPROXY falls back to direct"})
+		}
+
+		body, err := c.get(ctx, p.url+"/"+escaped+"/"+endpoint)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		var notFound notFoundError
+		if !errors.As(err, &notFound) && !p.fallbackOnError {
+			return proxyBody{}, err
+		}
+	}
+
+	if lastErr == nil {
+		return proxyBody{}, NoProxyError{ModulePath: modulePath, Reason: "GOPROXY is empty"}
+	}
+	return proxyBody{}, lastErr
+}
+
+// orNoProxy prefers the error of the last proxy tried, the module was not found there.
+func orNoProxy(lastErr error, err NoProxyError) error {
+	if lastErr != nil {
+		return lastErr
+	}
+	return err
+}
+
+// get opens the url from a http(s) or file:// proxy, the caller closes the body.
+func (c *ProxyClient) get(ctx context.Context, u string) (proxyBody, error) {
+	if strings.HasPrefix(u, "file://") {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return proxyBody{}, err
+		}
+		f, err := os.Open(filepath.FromSlash(parsed.Path))
+		if os.IsNotExist(err) {
+			return proxyBody{}, notFoundError{err: err}
+		}
+		if err != nil {
+			return proxyBody{}, err
+		}
+		size := int64(-1)
+		if info, err := f.Stat(); err == nil {
+			size = info.Size()
+		}
+		return proxyBody{ReadCloser: f, url: u, size: size}, nil
+	}
+
+	resp, err := get(ctx, c.Client, u)
+	if err != nil {
+		return proxyBody{}, NetworkError{URL: u, Err: err}
+	}
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		resp.Body.Close()
+		return proxyBody{}, notFoundError{err: StatusError{URL: u, StatusCode: resp.StatusCode}}
+	}
+	if err := checkResponse(u, resp); err != nil {
+		resp.Body.Close()
+		return proxyBody{}, err
+	}
+	return proxyBody{ReadCloser: resp.Body, url: u, size: resp.ContentLength}, nil
+}
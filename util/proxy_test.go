@@ -0,0 +1,151 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProxyClient(t *testing.T) {
+	ctx := context.Background()
+
+	// a file:// proxy, laid out like GOMODCACHE/cache/download
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml/@v/list"), "v1.2.0\nv1.3.2\n")
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml/@v/v1.3.2.info"), `{"Version":"v1.3.2","Time":"2023-06-08T06:11:55Z"}`)
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml/@v/v1.3.2.mod"), "module github.com/BurntSushi/toml\n")
+	writeFile(t, filepath.Join(dir, "github.com/!burnt!sushi/toml/@latest"), `{"Version":"v1.3.2"}`)
+	fileProxy := "file://" + filepath.ToSlash(dir)
+
+	t.Run("File proxy", func(t *testing.T) {
+		client := NewProxyClient(ProxyEnv{GOPROXY: fileProxy})
+
+		versions, err := client.List(ctx, "github.com/BurntSushi/toml")
+		if err != nil || !reflect.DeepEqual(versions, []string{"v1.3.2", "v1.2.0"}) {
+			t.Errorf("Expected [v1.3.2 v1.2.0], got %v, %v", versions, err)
+		}
+
+		info, err := client.Info(ctx, "github.com/BurntSushi/toml", "v1.3.2")
+		if err != nil || info.Version != "v1.3.2" || info.Time.IsZero() {
+			t.Errorf("Expected info of v1.3.2, got %+v, %v", info, err)
+		}
+
+		latest, err := client.Latest(ctx, "github.com/BurntSushi/toml")
+		if err != nil || latest.Version != "v1.3.2" {
+			t.Errorf("Expected latest v1.3.2, got %+v, %v", latest, err)
+		}
+
+		mod, err := client.GoMod(ctx, "github.com/BurntSushi/toml", "v1.3.2")
+		if err != nil || string(mod) != "module github.com/BurntSushi/toml\n" {
+			t.Errorf("Expected go.mod, got %q, %v", mod, err)
+		}
+	})
+
+	t.Run("Falls back when not found", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewProxyClient(ProxyEnv{GOPROXY: server.URL + "," + fileProxy})
+		versions, err := client.List(ctx, "github.com/BurntSushi/toml")
+		if err != nil || len(versions) != 2 || requests != 1 {
+			t.Errorf("Expected versions from the second proxy, got %v, %v after %d requests", versions, err, requests)
+		}
+	})
+
+	t.Run("Falls back on errors after a pipe", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := NewProxyClient(ProxyEnv{GOPROXY: server.URL + "," + fileProxy})
+		if _, err := client.List(ctx, "github.com/BurntSushi/toml"); err == nil {
+			t.Error("Expected the error of the first proxy with a comma, got nil")
+		}
+
+		client = NewProxyClient(ProxyEnv{GOPROXY: server.URL + "|" + fileProxy})
+		if _, err := client.List(ctx, "github.com/BurntSushi/toml"); err != nil {
+			t.Errorf("Expected versions from the second proxy with a pipe, got %v", err)
+		}
+	})
+
+	t.Run("No proxy", func(t *testing.T) {
+		tests := []struct {
+			name string
+			env  ProxyEnv
+		}{
+			{"Off", ProxyEnv{GOPROXY: "off"}},
+			{"Direct", ProxyEnv{GOPROXY: "direct"}},
+			{"Private", ProxyEnv{GOPROXY: fileProxy, GOPRIVATE: "github.com/BurntSushi"}},
+			{"No proxy over private", ProxyEnv{GOPROXY: fileProxy, GONOPROXY: "*.corp.example.com", GOPRIVATE: "github.com"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				modulePath := "github.com/BurntSushi/toml"
+				if tt.name == "No proxy over private" {
+					modulePath = "git.corp.example.com/tools"
+				}
+				_, err := NewProxyClient(tt.env).List(ctx, modulePath)
+				var noProxyErr NoProxyError
+				if !errors.As(err, &noProxyErr) {
+					t.Errorf("Expected NoProxyError, got %T: %v", err, err)
+				}
+			})
+		}
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		zipDir := t.TempDir()
+		writeFile(t, filepath.Join(zipDir, "github.com/!burnt!sushi/toml/@v/v1.3.2.zip"), "0123456789")
+		client := NewProxyClient(ProxyEnv{GOPROXY: "file://" + filepath.ToSlash(zipDir)})
+
+		f, err := client.Zip(ctx, "github.com/BurntSushi/toml", "v1.3.2", 10)
+		if err != nil {
+			t.Fatalf("Zip failed: %v", err)
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		if data, _ := os.ReadFile(f.Name()); string(data) != "0123456789" {
+			t.Errorf("Expected the zip in the temporary file, got %q", data)
+		}
+
+		var tooLarge ZipTooLargeError
+		if _, err := client.Zip(ctx, "github.com/BurntSushi/toml", "v1.3.2", 9); !errors.As(err, &tooLarge) {
+			t.Errorf("Expected ZipTooLargeError, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("Zip without content length", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// flushing switches to a chunked response, the size is not known up front
+			for i := 0; i < 4; i++ {
+				w.Write([]byte("0123456789"))
+				w.(http.Flusher).Flush()
+			}
+		}))
+		defer server.Close()
+
+		client := NewProxyClient(ProxyEnv{GOPROXY: server.URL})
+		var tooLarge ZipTooLargeError
+		if _, err := client.Zip(ctx, "github.com/BurntSushi/toml", "v1.3.2", 25); !errors.As(err, &tooLarge) {
+			t.Errorf("Expected ZipTooLargeError, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("Missing module", func(t *testing.T) {
+		_, err := NewProxyClient(ProxyEnv{GOPROXY: fileProxy}).List(ctx, "github.com/example/missing")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected a not found error, got %T: %v", err, err)
+		}
+	})
+}
@@ -0,0 +1,403 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// SourcePkgGoDev is the name of the default search backend that scrapes pkg.go.dev.
+	SourcePkgGoDev = "pkg.go.dev"
+	// SourceIndex is the name of the search backend that queries a JSON module index.
+	SourceIndex = "index"
+
+	PKG_GO_DEV_URL = "https://pkg.go.dev"
+	PKG_SEARCH_URL = "%s/search?m=package&%s"
+)
+
+// Searcher is implemented by anything that can look up go packages for a query.
+type Searcher interface {
+	// Search returns the results for the given term, best match first.
+	// The error is one of NetworkError, StatusError, RateLimitError or ParseError.
+	Search(ctx context.Context, term string) ([]SearchResult, error)
+}
+
+// SearchResult is a single package found by a Searcher. Only ImportPath is
+// guaranteed to be set, the other fields depend on what the backend provides.
+type SearchResult struct {
+	ImportPath string `json:"path"`
+	ModulePath string `json:"module,omitempty"`
+	Synopsis   string `json:"synopsis,omitempty"`
+	Version    string `json:"version,omitempty"`
+	ImportedBy int    `json:"importedBy,omitempty"`
+	License    string `json:"license,omitempty"`
+	Published  string `json:"published,omitempty"`
+}
+
+// Module returns the module path of the result, inferring it from the import path
+// when the backend did not report one.
+func (r SearchResult) Module() string {
+	if r.ModulePath != "" {
+		return r.ModulePath
+	}
+	return InferModulePath(r.ImportPath)
+}
+
+// NewSearcher returns the searcher registered under source. The baseURL overrides
+// the endpoint the searcher talks to, it is required for the index source. For the
+// offline source it overrides the module cache directory.
+func NewSearcher(source string, baseURL string) (Searcher, error) {
+	switch source {
+	case "", SourcePkgGoDev:
+		return NewPkgGoDevSearcher(baseURL), nil
+	case SourceIndex:
+		if baseURL == "" {
+			return nil, fmt.Errorf("search source %q requires a url", source)
+		}
+		return NewIndexSearcher(baseURL), nil
+	case SourceOffline:
+		return NewOfflineSearcher(baseURL), nil
+	default:
+		return nil, fmt.Errorf("unknown search source: %s", source)
+	}
+}
+
+// Search searches pkg.go.dev and returns the first 25 results.
+func Search(ctx context.Context, term string) ([]SearchResult, error) {
+	return NewPkgGoDevSearcher("").Search(ctx, term)
+}
+
+// MaxConcurrentSearches bounds how many searches run at the same time.
+const MaxConcurrentSearches = 4
+
+// SearchOutcome is the outcome of one of the searches of SearchAll.
+type SearchOutcome struct {
+	Results []SearchResult
+	Err     error
+}
+
+// SearchAll searches every term concurrently, running at most MaxConcurrentSearches at a time.
+// The outcomes are in the same order as the terms.
+func SearchAll(ctx context.Context, searcher Searcher, terms []string) []SearchOutcome {
+	outcomes := make([]SearchOutcome, len(terms))
+	slots := make(chan struct{}, MaxConcurrentSearches)
+
+	var wg sync.WaitGroup
+	for i, term := range terms {
+		wg.Add(1)
+		go func(i int, term string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				outcomes[i] = SearchOutcome{Err: ctx.Err()}
+				return
+			}
+
+			results, err := searcher.Search(ctx, term)
+			outcomes[i] = SearchOutcome{Results: results, Err: err}
+		}(i, term)
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// PkgGoDevSearcher scrapes the search page of pkg.go.dev, or any server that
+// serves the same markup.
+type PkgGoDevSearcher struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewPkgGoDevSearcher(baseURL string) *PkgGoDevSearcher {
+	if baseURL == "" {
+		baseURL = PKG_GO_DEV_URL
+	}
+	return &PkgGoDevSearcher{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Client:  httpClient,
+	}
+}
+
+// Search searches and parses the results from the search page and returns the first 25 results.
+func (s *PkgGoDevSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Add("q", term)
+	searchUrl := fmt.Sprintf(PKG_SEARCH_URL, s.BaseURL, params.Encode())
+	resp, err := get(ctx, s.Client, searchUrl)
+	if err != nil {
+		return nil, NetworkError{URL: searchUrl, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(searchUrl, resp); err != nil {
+		return nil, err
+	}
+
+	doc, err := html.Parse(resp.Body)
+	if err != nil {
+		return nil, ParseError{URL: searchUrl, Err: err}
+	}
+
+	res := parseResultsHtml(doc)
+
+	return res, nil
+}
+
+// IndexSearcher queries a module index that answers GET <url>?q=<term> with a
+// JSON array of SearchResult objects, only the "path" key is required.
+type IndexSearcher struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewIndexSearcher(indexURL string) *IndexSearcher {
+	return &IndexSearcher{
+		URL:    indexURL,
+		Client: httpClient,
+	}
+}
+
+func (s *IndexSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index url: %v", err)
+	}
+	params := u.Query()
+	params.Set("q", term)
+	u.RawQuery = params.Encode()
+	searchUrl := u.String()
+
+	resp, err := get(ctx, s.Client, searchUrl)
+	if err != nil {
+		return nil, NetworkError{URL: searchUrl, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(searchUrl, resp); err != nil {
+		return nil, err
+	}
+
+	var entries []SearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, ParseError{URL: searchUrl, Err: err}
+	}
+
+	res := make([]SearchResult, 0, len(entries))
+	for _, e := range entries {
+		if e.ImportPath != "" {
+			res = append(res, e)
+		}
+	}
+
+	return res, nil
+}
+
+// InferModulePath guesses the module path of an import path using the layout of
+// well known code hosts. Unknown hosts are assumed to be a module root.
+func InferModulePath(importPath string) string {
+	parts := strings.Split(importPath, "/")
+
+	n := len(parts)
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org", "golang.org", "google.golang.org":
+		n = 3
+		if parts[0] == "google.golang.org" {
+			n = 2
+		}
+	case "gopkg.in":
+		n = 2
+	}
+	if n > len(parts) {
+		return importPath
+	}
+	// keep the major version suffix, e.g. github.com/go-chi/chi/v5
+	if n < len(parts) && majorVersionRe.MatchString(parts[n]) {
+		n++
+	}
+	return strings.Join(parts[:n], "/")
+}
+
+var majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+// textContent returns the text of n and its children with whitespace collapsed.
+func textContent(n *html.Node) string {
+	var builder strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			builder.WriteString(n.Data)
+			builder.WriteString(" ")
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+func getAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func hasClass(n *html.Node, class string) bool {
+	v, _ := getAttr(n, "class")
+	for _, c := range strings.Fields(v) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// findAll returns every element under root, root included, that matches fn in document order.
+func findAll(root *html.Node, fn func(*html.Node) bool) []*html.Node {
+	var res []*html.Node
+
+	stack := []*html.Node{root}
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if n.Type == html.ElementNode && fn(n) {
+			res = append(res, n)
+		}
+
+		for c := n.LastChild; c != nil; c = c.PrevSibling {
+			stack = append(stack, c)
+		}
+	}
+
+	return res
+}
+
+func findFirst(root *html.Node, fn func(*html.Node) bool) *html.Node {
+	if found := findAll(root, fn); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+func byTestID(id string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		v, _ := getAttr(n, "data-test-id")
+		return v == id
+	}
+}
+
+// parseResultsHtml extracts a SearchResult for every result title (marked with data-gtmv)
+// in the pkg.go.dev search page.
+func parseResultsHtml(root *html.Node) []SearchResult {
+	var res []SearchResult
+
+	titles := findAll(root, func(n *html.Node) bool {
+		_, ok := getAttr(n, "data-gtmv")
+		return ok
+	})
+
+	for _, title := range titles {
+		if r, ok := parseSnippet(title); ok {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+// parseSnippet reads the result starting at its title node. The rest of the fields
+// are looked up in the closest SearchSnippet container.
+func parseSnippet(title *html.Node) (SearchResult, bool) {
+	var r SearchResult
+
+	if path := findFirst(title, func(n *html.Node) bool { return hasClass(n, "SearchSnippet-header-path") }); path != nil {
+		r.ImportPath = strings.Trim(textContent(path), "()")
+	} else if href, ok := getAttr(title, "href"); ok {
+		r.ImportPath = strings.TrimPrefix(strings.SplitN(href, "?", 2)[0], "/")
+	}
+	if r.ImportPath == "" {
+		return r, false
+	}
+
+	snippet := title
+	for snippet.Parent != nil && !hasClass(snippet, "SearchSnippet") {
+		snippet = snippet.Parent
+	}
+	if !hasClass(snippet, "SearchSnippet") {
+		return r, true
+	}
+
+	if n := findFirst(snippet, byTestID("snippet-module")); n != nil {
+		r.ModulePath = textContent(n)
+	}
+	if n := findFirst(snippet, byTestID("snippet-synopsis")); n != nil {
+		r.Synopsis = textContent(n)
+	}
+	if n := findFirst(snippet, byTestID("snippet-license")); n != nil {
+		r.License = textContent(n)
+	}
+	if n := findFirst(snippet, byTestID("snippet-published")); n != nil {
+		r.Published = textContent(n)
+		// the version is rendered right before the publish date: <strong>v1.2.3</strong> published on ...
+		if v := findFirst(n.Parent, func(n *html.Node) bool { return n.Data == "strong" }); v != nil && textContent(v) != r.Published {
+			r.Version = textContent(v)
+		}
+	}
+	if n := findFirst(snippet, byTestID("snippet-version")); n != nil {
+		r.Version = textContent(n)
+	}
+	importedBy := findFirst(snippet, func(n *html.Node) bool {
+		href, _ := getAttr(n, "href")
+		return strings.Contains(href, "tab=importedby")
+	})
+	if importedBy != nil {
+		if strong := findFirst(importedBy, func(n *html.Node) bool { return n.Data == "strong" }); strong != nil {
+			r.ImportedBy, _ = strconv.Atoi(strings.ReplaceAll(textContent(strong), ",", ""))
+		}
+	}
+
+	return r, true
+}
+
+// PickResult chooses the result to install for a term without asking. An exact match of the
+// import path wins, otherwise the first result is used. In strict mode several results
+// without an exact match are an error.
+func PickResult(term string, results []SearchResult, strict bool) (SearchResult, error) {
+	if len(results) == 0 {
+		return SearchResult{}, fmt.Errorf("no packages found for '%s'", term)
+	}
+
+	for _, r := range results {
+		if r.ImportPath == term {
+			return r, nil
+		}
+	}
+
+	if strict && len(results) > 1 {
+		candidates := make([]string, 0, 3)
+		for i := 0; i < len(results) && i < 3; i++ {
+			candidates = append(candidates, results[i].ImportPath)
+		}
+		return SearchResult{}, fmt.Errorf("'%s' is ambiguous, %d packages match (%s, ...)", term, len(results), strings.Join(candidates, ", "))
+	}
+
+	return results[0], nil
+}
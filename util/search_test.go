@@ -0,0 +1,310 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const searchPageFixture = `<!DOCTYPE html>
+<html>
+<body>
+  <div class="SearchSnippet">
+    <div class="SearchSnippet-headerContainer">
+      <h2>
+        <a href="/github.com/go-chi/chi/v5" data-gtmc="search result" data-gtmv="0" data-test-id="snippet-title">
+          chi
+          <span class="SearchSnippet-header-path">(github.com/go-chi/chi/v5)</span>
+        </a>
+      </h2>
+    </div>
+    <p class="SearchSnippet-synopsis" data-test-id="snippet-synopsis">
+      Package chi is a small,   idiomatic and composable router for building HTTP services.
+    </p>
+    <div class="SearchSnippet-infoLabel">
+      <a href="/github.com/go-chi/chi/v5?tab=importedby" aria-label="Go to Imported By">
+        <span class="go-textSubtle">Imported by </span><strong>12,345</strong>
+      </a>
+      <span class="go-textSubtle">|</span>
+      <span class="go-textSubtle">
+        <strong>v5.0.12</strong> published on <span data-test-id="snippet-published"><strong>Feb 16, 2024</strong></span>
+      </span>
+      <span class="go-textSubtle">|</span>
+      <span data-test-id="snippet-license">
+        <a href="/github.com/go-chi/chi/v5?tab=licenses" aria-label="Go to Licenses">MIT</a>
+      </span>
+    </div>
+  </div>
+  <div class="SearchSnippet">
+    <div class="SearchSnippet-headerContainer">
+      <h2>
+        <a href="/github.com/go-chi/cors" data-gtmc="search result" data-gtmv="1" data-test-id="snippet-title">
+          cors
+          <span class="SearchSnippet-header-path">(github.com/go-chi/cors)</span>
+        </a>
+      </h2>
+    </div>
+  </div>
+</body>
+</html>`
+
+func TestNewSearcher(t *testing.T) {
+	s, err := NewSearcher("", "")
+	if err != nil {
+		t.Fatalf("NewSearcher failed for default source: %v", err)
+	}
+	if _, ok := s.(*PkgGoDevSearcher); !ok {
+		t.Errorf("Expected default source to be *PkgGoDevSearcher, got %T", s)
+	}
+
+	// Index source requires a url
+	if _, err := NewSearcher(SourceIndex, ""); err == nil {
+		t.Error("Expected error when index source has no url, got nil")
+	}
+
+	if _, err := NewSearcher("unknown", ""); err == nil {
+		t.Error("Expected error for unknown source, got nil")
+	}
+}
+
+func TestPkgGoDevSearcher(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.Query().Get("q")
+		w.Write([]byte(searchPageFixture))
+	}))
+	defer server.Close()
+
+	results, err := NewPkgGoDevSearcher(server.URL).Search(context.Background(), "chi")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if gotQuery != "chi" {
+		t.Errorf("Expected query 'chi', got '%s'", gotQuery)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	expected := SearchResult{
+		ImportPath: "github.com/go-chi/chi/v5",
+		Synopsis:   "Package chi is a small, idiomatic and composable router for building HTTP services.",
+		Version:    "v5.0.12",
+		ImportedBy: 12345,
+		License:    "MIT",
+		Published:  "Feb 16, 2024",
+	}
+	if results[0] != expected {
+		t.Errorf("Expected first result %+v, got %+v", expected, results[0])
+	}
+	if module := results[0].Module(); module != "github.com/go-chi/chi/v5" {
+		t.Errorf("Expected module 'github.com/go-chi/chi/v5', got '%s'", module)
+	}
+
+	// Results without the snippet details still carry the import path
+	if results[1].ImportPath != "github.com/go-chi/cors" {
+		t.Errorf("Expected second result 'github.com/go-chi/cors', got '%s'", results[1].ImportPath)
+	}
+}
+
+func TestInferModulePath(t *testing.T) {
+	tests := map[string]string{
+		"github.com/go-chi/chi/v5/middleware": "github.com/go-chi/chi/v5",
+		"github.com/spf13/cobra":              "github.com/spf13/cobra",
+		"golang.org/x/net/html":               "golang.org/x/net",
+		"google.golang.org/grpc/credentials":  "google.golang.org/grpc",
+		"gopkg.in/yaml.v3":                    "gopkg.in/yaml.v3",
+		"corp.example.com/platform/auth":      "corp.example.com/platform/auth",
+	}
+
+	for importPath, expected := range tests {
+		if got := InferModulePath(importPath); got != expected {
+			t.Errorf("InferModulePath(%s): expected '%s', got '%s'", importPath, expected, got)
+		}
+	}
+}
+
+func TestIndexSearcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]string{
+			{"path": "corp.example.com/platform/" + r.URL.Query().Get("q")},
+		})
+	}))
+	defer server.Close()
+
+	results, err := NewIndexSearcher(server.URL+"/search").Search(context.Background(), "auth")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if results[0].ImportPath != "corp.example.com/platform/auth" {
+		t.Errorf("Expected 'corp.example.com/platform/auth', got '%s'", results[0].ImportPath)
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	t.Run("Rate limited", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		_, err := NewPkgGoDevSearcher(server.URL).Search(context.Background(), "chi")
+
+		var rateLimitErr RateLimitError
+		if !errors.As(err, &rateLimitErr) {
+			t.Fatalf("Expected RateLimitError, got %T: %v", err, err)
+		}
+		if rateLimitErr.RetryAfter != 30*time.Second {
+			t.Errorf("Expected retry after 30s, got %s", rateLimitErr.RetryAfter)
+		}
+	})
+
+	t.Run("Unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		_, err := NewIndexSearcher(server.URL).Search(context.Background(), "auth")
+
+		var statusErr StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("Expected StatusError with code 500, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("Unparseable page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("<html>not json</html>"))
+		}))
+		defer server.Close()
+
+		_, err := NewIndexSearcher(server.URL).Search(context.Background(), "auth")
+
+		var parseErr ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("Expected ParseError, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("Network failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		_, err := NewPkgGoDevSearcher(server.URL).Search(context.Background(), "chi")
+
+		var networkErr NetworkError
+		if !errors.As(err, &networkErr) {
+			t.Fatalf("Expected NetworkError, got %T: %v", err, err)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		block := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-block:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(block)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := NewPkgGoDevSearcher(server.URL).Search(ctx, "chi")
+
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected the search to be cancelled, got %T: %v", err, err)
+		}
+	})
+}
+
+func TestPickResult(t *testing.T) {
+	results := []SearchResult{
+		{ImportPath: "github.com/go-chi/chi"},
+		{ImportPath: "github.com/go-chi/chi/v5"},
+	}
+
+	r, err := PickResult("chi", results, false)
+	if err != nil || r.ImportPath != "github.com/go-chi/chi" {
+		t.Errorf("Expected first result without strict mode, got %+v, %v", r, err)
+	}
+
+	if _, err := PickResult("chi", results, true); err == nil {
+		t.Error("Expected error for ambiguous query in strict mode, got nil")
+	}
+
+	// An exact match is never ambiguous
+	r, err = PickResult("github.com/go-chi/chi/v5", results, true)
+	if err != nil || r.ImportPath != "github.com/go-chi/chi/v5" {
+		t.Errorf("Expected exact match in strict mode, got %+v, %v", r, err)
+	}
+
+	if _, err := PickResult("chi", nil, false); err == nil {
+		t.Error("Expected error when nothing matched, got nil")
+	}
+}
+
+// slowSearcher echoes the term back as a result and tracks how many searches run at once
+type slowSearcher struct {
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (s *slowSearcher) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		peak := s.peak.Load()
+		if n <= peak || s.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if term == "fail" {
+		return nil, errors.New("search failed")
+	}
+	return []SearchResult{{ImportPath: term}}, nil
+}
+
+func TestSearchAll(t *testing.T) {
+	terms := []string{"a", "b", "fail", "c", "d", "e", "f", "g", "h"}
+	searcher := &slowSearcher{}
+
+	outcomes := SearchAll(context.Background(), searcher, terms)
+
+	if len(outcomes) != len(terms) {
+		t.Fatalf("Expected %d outcomes, got %d", len(terms), len(outcomes))
+	}
+	for i, term := range terms {
+		if term == "fail" {
+			if outcomes[i].Err == nil {
+				t.Errorf("Expected search of '%s' to fail", term)
+			}
+			continue
+		}
+		if outcomes[i].Err != nil || len(outcomes[i].Results) != 1 || outcomes[i].Results[0].ImportPath != term {
+			t.Errorf("Expected outcome %d to be '%s', got %+v", i, term, outcomes[i])
+		}
+	}
+	if peak := searcher.peak.Load(); peak > MaxConcurrentSearches {
+		t.Errorf("Expected at most %d concurrent searches, got %d", MaxConcurrentSearches, peak)
+	}
+}
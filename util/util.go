@@ -3,66 +3,261 @@ package util
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
 	"os"
-	"os/exec"
-	"regexp"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 
-	"golang.org/x/net/html"
-)
-
-const (
-	PKG_SEARCH_URL = "https://pkg.go.dev/search?m=package&%s"
+	"golang.org/x/mod/semver"
 )
 
 type Package struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Dir      string `json:"dir"`
+	Main     bool   `json:"main"`
+	Indirect bool   `json:"indirect"`
+	// Update is the newest available version, only set when listing with -u
+	Update *PackageUpdate `json:"update,omitempty"`
+	// Deprecated is the deprecation message of the module, only set when listing with -u
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// DependencyType describes if the package is the main module, a direct or an indirect dependency
+func (p Package) DependencyType() string {
+	if p.Main {
+		return "main"
+	}
+	if p.Indirect {
+		return "indirect"
+	}
+	return "direct"
+}
+
+// UpdateVersion returns the version of the available update, "" when the package is up to date.
+func (p Package) UpdateVersion() string {
+	if p.Update == nil {
+		return ""
+	}
+	return p.Update.Version
+}
+
+// PackageUpdate is an available upgrade of a Package
+type PackageUpdate struct {
 	Path    string `json:"path"`
 	Version string `json:"version"`
-	Dir     string `json:"dir"`
 }
 
-func GetPkgUrl(value string) string {
-	re := regexp.MustCompile(`\((.*?)\)`)
-	match := re.FindStringSubmatch(value)
-	if len(match) > 1 {
-		return match[1]
+const (
+	BumpMajor      = "major"
+	BumpMinor      = "minor"
+	BumpPatch      = "patch"
+	BumpPrerelease = "prerelease"
+)
+
+// Bump classifies the upgrade from one version to another as major, minor, patch or prerelease.
+func Bump(from string, to string) string {
+	switch {
+	case semver.Major(from) != semver.Major(to):
+		return BumpMajor
+	case semver.MajorMinor(from) != semver.MajorMinor(to):
+		return BumpMinor
+	case semver.Prerelease(to) != "":
+		return BumpPrerelease
+	default:
+		return BumpPatch
 	}
-	return match[0]
 }
 
-// Search searches and parses the results from pkg.go.dev and returns the first 25 results.
-func Search(term string) []string {
-	params := url.Values{}
-	params.Add("q", term)
-	searchUrl := fmt.Sprintf(PKG_SEARCH_URL, params.Encode())
-	resp, err := http.Get(searchUrl)
+const (
+	StatusOK            = "ok"
+	StatusSearchFailed  = "search_failed"
+	StatusNoMatch       = "no_match"
+	StatusAmbiguous     = "ambiguous"
+	StatusInstallFailed = "install_failed"
+	// StatusCancelled is for the queries left when the user quit before installing them
+	StatusCancelled = "cancelled"
+)
+
+// InstallResult is the outcome of a query given to gop get or gop install
+type InstallResult struct {
+	Query   string `json:"query"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Status is one of StatusOK, StatusSearchFailed, StatusNoMatch, StatusAmbiguous, StatusInstallFailed or StatusCancelled
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RunGoGet is the same as RunGoInstall but it uses 'go get' instead of 'go install'.
+// Several packages are resolved together in a single 'go get'.
+func RunGoGet(ctx context.Context, pkgs ...string) error {
+	return GoRunner{}.Get(ctx, pkgs...)
+}
+
+// RunGoInstall builds and installs the binary of pkg with 'go install'. The pkg must
+// carry a version, e.g. golang.org/x/tools/cmd/stringer@latest.
+func RunGoInstall(ctx context.Context, pkg string) error {
+	return GoRunner{}.Install(ctx, pkg)
+}
+
+// BinaryDir returns the directory where 'go install' puts binaries, GOBIN or GOPATH/bin.
+func BinaryDir(ctx context.Context) (string, error) {
+	output, err := goOutput(ctx, "env", "GOBIN", "GOPATH")
 	if err != nil {
-		panic(err)
+		return "", fmt.Errorf("error executing command: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if gobin := strings.TrimSpace(lines[0]); gobin != "" {
+		return gobin, nil
 	}
-	defer resp.Body.Close()
+	if len(lines) < 2 || strings.TrimSpace(lines[1]) == "" {
+		return "", fmt.Errorf("neither GOBIN nor GOPATH is set")
+	}
+	// GOPATH may hold a list of paths, go install uses the first one
+	gopath := filepath.SplitList(strings.TrimSpace(lines[1]))[0]
+	return filepath.Join(gopath, "bin"), nil
+}
 
-	doc, err := html.Parse(resp.Body)
+// BinaryName returns the name of the binary 'go install' builds for pkg,
+// the last path element ignoring a major version suffix.
+func BinaryName(pkg string) string {
+	pkg, _ = SplitQuery(pkg)
+	parts := strings.Split(pkg, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && majorVersionRe.MatchString(name) {
+		name = parts[len(parts)-2]
+	}
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// BinaryVersion reads the module version a go binary was built from.
+func BinaryVersion(ctx context.Context, binPath string) (string, error) {
+	output, err := goOutput(ctx, "version", "-m", binPath)
 	if err != nil {
-		panic(err)
+		return "", fmt.Errorf("error executing command: %v", err)
+	}
+
+	// the main module is listed as: mod <path> <version> <sum>
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "mod" {
+			return fields[2], nil
+		}
 	}
+	return "", fmt.Errorf("no module information in %s", binPath)
+}
 
-	res := parseResultsHtml(doc)
+// RemoveModule drops the module and everything that depends on it from go.mod with 'go get module@none'.
+func RemoveModule(ctx context.Context, module string) error {
+	return GoRunner{}.Run(ctx, "get", module+"@none")
+}
 
-	return res
+// RunGoModTidy runs 'go mod tidy' in the current module.
+func RunGoModTidy(ctx context.Context) error {
+	return GoRunner{}.Run(ctx, "mod", "tidy")
 }
 
-// RunGoGet is the same as RunGoInstall but it uses 'go get' instead of 'go install'.
-func RunGoGet(pkg string) error {
-	cmd := exec.Command("go", "get", pkg)
-	err := cmd.Run()
+// ModuleVersion returns the version of the module selected in the build list of the current module.
+func ModuleVersion(ctx context.Context, module string) (string, error) {
+	output, err := goOutput(ctx, "list", "-m", "-f", "{{.Version}}", module)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("error executing command: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ModWhy returns the output of 'go mod why -m', the shortest import chain from the main module to the module.
+func ModWhy(ctx context.Context, module string) (string, error) {
+	output, err := goOutput(ctx, "mod", "why", "-m", module)
+	if err != nil {
+		return "", fmt.Errorf("error executing command: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// BackupModFiles saves the contents of go.mod and go.sum, the returned function writes them back.
+func BackupModFiles() (restore func() error, err error) {
+	files := map[string][]byte{}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(name)
+		if os.IsNotExist(err) && name == "go.sum" {
+			// a module without dependencies has no go.sum
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error reading %s file: %v", name, err)
+		}
+		files[name] = data
+	}
+
+	restore = func() error {
+		for name, data := range files {
+			if err := os.WriteFile(name, data, 0644); err != nil {
+				return fmt.Errorf("Error restoring %s file: %v", name, err)
+			}
+		}
+		if _, ok := files["go.sum"]; !ok {
+			os.Remove("go.sum")
+		}
+		return nil
 	}
-	return nil
+	return restore, nil
+}
+
+// SortDependencies orders the packages with direct dependencies first and then by path.
+func SortDependencies(packages []Package) {
+	sort.SliceStable(packages, func(i, j int) bool {
+		if packages[i].Indirect != packages[j].Indirect {
+			return !packages[i].Indirect
+		}
+		return packages[i].Path < packages[j].Path
+	})
+}
+
+// FindImporters returns the packages of the current module that import a package of the given module,
+// from their code or their tests.
+func FindImporters(ctx context.Context, module string) ([]string, error) {
+	format := `{{.ImportPath}}{{range .Imports}} {{.}}{{end}}{{range .TestImports}} {{.}}{{end}}{{range .XTestImports}} {{.}}{{end}}`
+	output, err := goOutput(ctx, "list", "-e", "-f", format, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("error executing command: %v", err)
+	}
+
+	var importers []string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, imp := range fields[1:] {
+			if imp == module || strings.HasPrefix(imp, module+"/") {
+				importers = append(importers, fields[0])
+				break
+			}
+		}
+	}
+
+	return importers, nil
+}
+
+// DirectDependencies filters out the main module and the indirect dependencies.
+func DirectDependencies(packages []Package) []Package {
+	var direct []Package
+	for _, pkg := range packages {
+		if !pkg.Main && !pkg.Indirect {
+			direct = append(direct, pkg)
+		}
+	}
+	return direct
 }
 
 func ParseGoMod() ([]string, error) {
@@ -132,74 +327,55 @@ func extractModuleFromLine(singular bool, line string) string {
 	return module + "@" + version
 }
 
-func GetDependencyList() ([]Package, error) {
-	output, err := exec.Command("go", "list", "-m", "-json", "all").Output()
+func GetDependencyList(ctx context.Context) ([]Package, error) {
+	return listModules(ctx)
+}
+
+// GetOutdatedDependencies returns the modules in the build list that have an update available
+// or are deprecated, a deprecated module can be at its latest version.
+func GetOutdatedDependencies(ctx context.Context) ([]Package, error) {
+	packages, err := listModules(ctx, "-u")
 	if err != nil {
-		return nil, fmt.Errorf("error executing command: %v", err)
+		return nil, err
 	}
 
-	decoder := json.NewDecoder(bytes.NewReader(output))
-	var packages []Package
-
-	for decoder.More() {
-		var pkg Package
-		if err := decoder.Decode(&pkg); err != nil {
-			return nil, fmt.Errorf("error parsing JSON: %v", err)
+	var outdated []Package
+	for _, pkg := range packages {
+		if pkg.Update != nil || pkg.Deprecated != "" {
+			outdated = append(outdated, pkg)
 		}
-		packages = append(packages, pkg)
 	}
-	return packages, nil
+	return outdated, nil
 }
 
-func getText(n *html.Node) string {
-	if n.Type == html.TextNode {
-		return n.Data
-	}
-
-	var result strings.Builder
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		r := getText(c)
-		if r != "" {
-			result.WriteString(strings.TrimSpace(r))
+// UpgradableDependencies filters out the packages without an update available.
+func UpgradableDependencies(packages []Package) []Package {
+	var upgradable []Package
+	for _, pkg := range packages {
+		if pkg.Update != nil {
+			upgradable = append(upgradable, pkg)
 		}
 	}
-
-	return result.String()
+	return upgradable
 }
 
-func getLastChild(n *html.Node) *html.Node {
-	var lastChild *html.Node
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		lastChild = c
+func listModules(ctx context.Context, flags ...string) ([]Package, error) {
+	args := append([]string{"list", "-m", "-json"}, flags...)
+	args = append(args, "all")
+	output, err := goOutput(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing command: %v", err)
 	}
-	return lastChild
-}
-
-func getPrevSibling(n *html.Node) *html.Node {
-	return n.PrevSibling
-}
-
-func parseResultsHtml(root *html.Node) []string {
-	var res []string
 
-	stack := []*html.Node{root}
-
-	for len(stack) > 0 {
-		n := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
-
-		if n.Type == html.ElementNode {
-			for _, a := range n.Attr {
-				if a.Key == "data-gtmv" {
-					res = append(res, getText(n))
-				}
-			}
-		}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	var packages []Package
 
-		for c := getLastChild(n); c != nil; c = getPrevSibling(c) {
-			stack = append(stack, c)
+	for decoder.More() {
+		var pkg Package
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("error parsing JSON: %v", err)
 		}
+		packages = append(packages, pkg)
 	}
-
-	return res
+	return packages, nil
 }
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		bump string
+	}{
+		{"v1.2.3", "v1.2.4", BumpPatch},
+		{"v1.2.3", "v1.3.0", BumpMinor},
+		{"v0.9.1", "v1.0.0", BumpMajor},
+		{"v1.2.3", "v1.2.4-rc.1", BumpPrerelease},
+		{"v0.0.0-20231006140011-7918f672742d", "v0.0.0-20240102150405-abcdef123456", BumpPrerelease},
+	}
+
+	for _, tt := range tests {
+		if got := Bump(tt.from, tt.to); got != tt.bump {
+			t.Errorf("Bump(%s, %s): expected '%s', got '%s'", tt.from, tt.to, tt.bump, got)
+		}
+	}
+}
+
+func TestDirectDependencies(t *testing.T) {
+	packages := []Package{
+		{Path: "github.com/juancwu/gopack", Main: true},
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "github.com/spf13/pflag", Version: "v1.0.5", Indirect: true},
+	}
+
+	direct := DirectDependencies(packages)
+
+	if len(direct) != 1 {
+		t.Fatalf("Expected 1 direct dependency, got %d", len(direct))
+	}
+	if direct[0].Path != "github.com/spf13/cobra" {
+		t.Errorf("Expected 'github.com/spf13/cobra', got '%s'", direct[0].Path)
+	}
+}
+
+func TestUpgradableDependencies(t *testing.T) {
+	packages := []Package{
+		{Path: "github.com/spf13/cobra", Version: "v1.7.0", Update: &PackageUpdate{Path: "github.com/spf13/cobra", Version: "v1.8.0"}},
+		{Path: "github.com/golang/protobuf", Version: "v1.5.4", Deprecated: "Use the google.golang.org/protobuf module instead."},
+	}
+
+	upgradable := UpgradableDependencies(packages)
+
+	if len(upgradable) != 1 {
+		t.Fatalf("Expected 1 upgradable dependency, got %d", len(upgradable))
+	}
+	if upgradable[0].UpdateVersion() != "v1.8.0" {
+		t.Errorf("Expected 'v1.8.0', got '%s'", upgradable[0].UpdateVersion())
+	}
+	if packages[1].UpdateVersion() != "" {
+		t.Errorf("Expected no update version, got '%s'", packages[1].UpdateVersion())
+	}
+}
+
+func TestBackupModFiles(t *testing.T) {
+	// Save and restore working directory
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+
+	// Create a temporary directory and change to it
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	original := "module example.com/test\n\ngo 1.21\n"
+	if err := os.WriteFile("go.mod", []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+
+	restore, err := BackupModFiles()
+	if err != nil {
+		t.Fatalf("BackupModFiles failed: %v", err)
+	}
+
+	// Simulate an upgrade that touches both files
+	os.WriteFile("go.mod", []byte(original+"\nrequire example.com/dep v1.0.0\n"), 0644)
+	os.WriteFile("go.sum", []byte("example.com/dep v1.0.0 h1:abc=\n"), 0644)
+
+	if err := restore(); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	data, _ := os.ReadFile("go.mod")
+	if string(data) != original {
+		t.Errorf("Expected go.mod to be restored, got: %s", data)
+	}
+	if _, err := os.Stat("go.sum"); !os.IsNotExist(err) {
+		t.Error("Expected go.sum created after the backup to be removed")
+	}
+}
+
+func TestFindImporters(t *testing.T) {
+	// Save and restore working directory
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change to temp directory: %v", err)
+	}
+
+	files := map[string]string{
+		"go.mod":            "module example.com/test\n\ngo 1.21\n",
+		"main.go":           "package main\n\nimport _ \"example.com/dep\"\n\nfunc main() {}\n",
+		"lib/lib.go":        "package lib\n",
+		"lib/lib_test.go":   "package lib\n\nimport _ \"example.com/dep/assert\"\n",
+		"ext/ext.go":        "package ext\n",
+		"ext/ext_test.go":   "package ext_test\n\nimport _ \"example.com/dep\"\n",
+		"none/none.go":      "package none\n\nimport _ \"example.com/dependency\"\n",
+		"none/none_test.go": "package none\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	importers, err := FindImporters(context.Background(), "example.com/dep")
+	if err != nil {
+		t.Fatalf("FindImporters failed: %v", err)
+	}
+	expected := []string{"example.com/test", "example.com/test/ext", "example.com/test/lib"}
+	if strings.Join(importers, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, importers)
+	}
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// DEFAULT_PROXY_URL is the module proxy used when GOPROXY is not set
+const DEFAULT_PROXY_URL = "https://proxy.golang.org"
+
+// SplitQuery splits a query in the form term@version. The version is empty
+// when the query does not pin one, e.g. "chi" or "chi@" both return "chi" and "".
+func SplitQuery(query string) (term string, version string) {
+	i := strings.LastIndex(query, "@")
+	if i < 0 {
+		return query, ""
+	}
+	return query[:i], query[i+1:]
+}
+
+// JoinVersion appends the version to the package path for go get, leaving the path as is if version is empty.
+func JoinVersion(pkg string, version string) string {
+	if version == "" {
+		return pkg
+	}
+	return pkg + "@" + version
+}
+
+// ListVersions returns the released versions of a module, newest first, from the
+// module proxies in GOPROXY. Modules that can't go through a proxy, like the GOPRIVATE
+// ones, are listed by the go command from their code host.
+func ListVersions(ctx context.Context, modulePath string) ([]string, error) {
+	client, err := NewProxyClientFromEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := client.List(ctx, modulePath)
+	var noProxyErr NoProxyError
+	if errors.As(err, &noProxyErr) {
+		return listVersionsDirect(ctx, modulePath)
+	}
+	return versions, err
+}
+
+// NewerVersions returns the versions greater than current, keeping their order.
+func NewerVersions(versions []string, current string) []string {
+	var newer []string
+	for _, v := range versions {
+		if semver.Compare(v, current) > 0 {
+			newer = append(newer, v)
+		}
+	}
+	return newer
+}
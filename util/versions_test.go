@@ -0,0 +1,55 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSplitQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		term    string
+		version string
+	}{
+		{"chi", "chi", ""},
+		{"chi@v5.0.12", "chi", "v5.0.12"},
+		{"github.com/go-chi/chi/v5@latest", "github.com/go-chi/chi/v5", "latest"},
+		{"cors@master", "cors", "master"},
+		{"chi@", "chi", ""},
+	}
+
+	for _, tt := range tests {
+		term, version := SplitQuery(tt.query)
+		if term != tt.term || version != tt.version {
+			t.Errorf("SplitQuery(%s): expected (%s, %s), got (%s, %s)", tt.query, tt.term, tt.version, term, version)
+		}
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte("v1.2.0\nv1.10.0\nv1.9.3\nnot-a-version\nv2.0.0-rc.1\n"))
+	}))
+	defer server.Close()
+
+	t.Setenv("GOPROXY", server.URL+",direct")
+
+	versions, err := ListVersions(context.Background(), "github.com/BurntSushi/toml")
+	if err != nil {
+		t.Fatalf("ListVersions failed: %v", err)
+	}
+
+	if gotPath != "/github.com/!burnt!sushi/toml/@v/list" {
+		t.Errorf("Expected escaped module path in request, got '%s'", gotPath)
+	}
+
+	expected := []string{"v2.0.0-rc.1", "v1.10.0", "v1.9.3", "v1.2.0"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("Expected versions %v, got %v", expected, versions)
+	}
+}